@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/gen2thomas/gobrail/internal/app/config"
 	"github.com/gen2thomas/gobrail/internal/app/gobrailcreator"
+	"github.com/gen2thomas/gobrail/internal/boardrecipe"
 )
 
 var rail gobrailcreator.RailRunner
@@ -60,22 +62,57 @@ func main() {
 		log.Println(err)
 		os.Exit(1)
 	}
+	if conf.Scan {
+		if err = scan(*conf); err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if conf.WriteID {
+		if err = gobrailcreator.WriteIdentities(conf.AdaptorType, conf.PlanFile); err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
 	if err = reinit(*conf); err != nil {
-		log.Println(err)
-		os.Exit(1)
+		fatal(err)
 	}
 	if err = run(ctx, *conf, reloadChan); err != nil {
-		log.Println(err)
-		os.Exit(1)
+		fatal(err)
 	}
 	log.Println("end")
 }
 
+// fatal brings all outputs to the safe state before exit, so no coil is left energized
+func fatal(err error) {
+	log.Println(err)
+	if errStop := gobrailcreator.Stop(); errStop != nil {
+		log.Printf("Error while stopping: %s", errStop.Error())
+	}
+	os.Exit(1)
+}
+
 func reinit(c config.Config) (err error) {
+	gobrailcreator.SetCoalescedWrites(c.Coalesce)
 	rail, err = gobrailcreator.Create(true, "Model railroad prototype", c.AdaptorType, c.PlanFile, gobrailcreator.RecipeFiles{})
 	return
 }
 
+func scan(c config.Config) (err error) {
+	var recipes []boardrecipe.Ingredients
+	if recipes, err = gobrailcreator.Discover(c.AdaptorType, c.PlanFile); err != nil {
+		return
+	}
+	var recipesJSON []byte
+	if recipesJSON, err = json.MarshalIndent(recipes, "", "  "); err != nil {
+		return
+	}
+	fmt.Printf("Found %d boards:\n%s\n", len(recipes), recipesJSON)
+	return
+}
+
 func run(ctx context.Context, conf config.Config, reloadChan <-chan bool) (err error) {
 	// https://forum.golangbridge.org/t/runtime-siftdowntimer-consuming-60-of-the-cpu/3773
 	ticker := time.NewTicker(conf.Tick)
@@ -85,6 +122,8 @@ func run(ctx context.Context, conf config.Config, reloadChan <-chan bool) (err e
 	// first loops takes >1 sec, so skip it for measurement
 	count := -1
 	const testCycles = 100
+	// an error is reported only once until it changes, e.g. a disturbed board is reported when it goes offline
+	var lastRunErr string
 	for {
 		select {
 		case <-ctx.Done():
@@ -94,8 +133,7 @@ func run(ctx context.Context, conf config.Config, reloadChan <-chan bool) (err e
 			ticker.Stop()
 			log.Printf("Wait finishing loop")
 			if err = reinit(conf); err != nil {
-				fmt.Errorf("Create rail with new configuration has error: %s", err.Error())
-				return
+				return fmt.Errorf("Create rail with new configuration has error: %w", err)
 			}
 			count = -1
 			ticker = time.NewTicker(conf.Tick)
@@ -104,8 +142,12 @@ func run(ctx context.Context, conf config.Config, reloadChan <-chan bool) (err e
 			if count == 1 {
 				start = time.Now()
 			}
-			if err = rail.Run(); err != nil {
-				return
+			// a disturbed board is handled by the boards API, so the railroad keeps running
+			if err = rail.Run(); err == nil {
+				lastRunErr = ""
+			} else if err.Error() != lastRunErr {
+				lastRunErr = err.Error()
+				log.Println(err)
 			}
 			if count == testCycles {
 				count = -1
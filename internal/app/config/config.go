@@ -17,6 +17,9 @@ type Config struct {
 	PlanFile    string
 	AdaptorType gobrailcreator.AdaptorType
 	Tick        time.Duration
+	Coalesce    bool
+	Scan        bool
+	WriteID     bool
 }
 
 // Fill will parse the command line and fill the configuration object
@@ -25,11 +28,17 @@ func (c *Config) Fill() (err error) {
 	planFile := flag.String("plan", defaultPlan, "Path to railroad plan file")
 	adaptorType := flag.String("adaptor", defaultAdaptor, "Supported adaptors are "+supportedAdaptors)
 	tick := flag.Duration("tick", defaultTick, "Ticking interval, 10ms ... 50ms would be sufficient")
+	scan := flag.Bool("scan", false, "Search for not used boards at the bus and print the recipes, the plan is used to skip known boards")
+	writeID := flag.Bool("writeid", false, "Write the identity (name, type) of all boards of the plan to the EEPROM of the board")
+	coalesce := flag.Bool("coalesce", false, "Write all output changes of a chip once per tick, turnout delays need to be greater than the tick")
 	flag.Parse()
 
 	c.PlanFile = *planFile
 	log.Println(c.PlanFile)
 	c.AdaptorType, err = gobrailcreator.ParseAdaptorType(*adaptorType)
 	c.Tick = *tick
+	c.Coalesce = *coalesce
+	c.Scan = *scan
+	c.WriteID = *writeID
 	return
 }
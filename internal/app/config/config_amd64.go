@@ -3,4 +3,4 @@ package config
 // special implementation part for amd64 (x86) targets
 
 var defaultAdaptor = "Digispark"
-var supportedAdaptors = "'Digispark'"
+var supportedAdaptors = "'Digispark', 'Sim'"
@@ -3,4 +3,4 @@ package config
 // special implementation part for arm targets
 
 var defaultAdaptor = "Raspi"
-var supportedAdaptors = "'Raspi', 'Tinkerboard'"
+var supportedAdaptors = "'Raspi', 'Tinkerboard', 'Sim'"
@@ -7,14 +7,18 @@ package gobrailcreator
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/drivers/i2c"
 
+	"github.com/gen2thomas/gobrail/internal/boardrecipe"
 	"github.com/gen2thomas/gobrail/internal/boardsapi"
+	"github.com/gen2thomas/gobrail/internal/errwrap"
 	"github.com/gen2thomas/gobrail/internal/raildevicesapi"
 	"github.com/gen2thomas/gobrail/internal/railplan"
+	"github.com/gen2thomas/gobrail/internal/simadaptor"
 )
 
 // RailRunner is an interface to poll the rail
@@ -22,6 +26,18 @@ type RailRunner interface {
 	Run() (err error)
 }
 
+// railDevicesRunner is an interface to poll the rail devices and bring its outputs to a safe state
+type railDevicesRunner interface {
+	RailRunner
+	ApplySafeStates() (err error)
+}
+
+// cycleRunner begins a new cycle for the boards before running the rail devices
+type cycleRunner struct {
+	boardsAPI *boardsapi.BoardsAPI
+	deviceAPI railDevicesRunner
+}
+
 type i2cAdaptor interface {
 	i2c.Connector
 	gobot.Connection
@@ -34,6 +50,7 @@ const (
 	digisparkType AdaptorType = iota
 	raspiType
 	tinkerboardType
+	simType
 	unknownType
 )
 
@@ -43,16 +60,29 @@ type RecipeFiles struct {
 	Devices []string
 }
 
-var adaptorTypeToStringMap = map[AdaptorType]string{digisparkType: "digispark", raspiType: "raspi", tinkerboardType: "tinkerboard", unknownType: "typUnknown"}
-var adaptorStringToTypeMap = map[string]AdaptorType{"digispark": digisparkType, "raspi": raspiType, "tinkerboard": tinkerboardType, "unknown": unknownType}
+var adaptorTypeToStringMap = map[AdaptorType]string{digisparkType: "digispark", raspiType: "raspi", tinkerboardType: "tinkerboard", simType: "sim", unknownType: "typUnknown"}
+var adaptorStringToTypeMap = map[string]AdaptorType{"digispark": digisparkType, "raspi": raspiType, "tinkerboard": tinkerboardType, "sim": simType, "unknown": unknownType}
 
 var lastGobot *gobot.Robot
 
+// the safe state of outputs is applied on stop, only available after the boards are verified
+var lastCycle *cycleRunner
+
+// the robot can be stopped by a signal while a reload creates a new one
+var lastGobotMutex sync.Mutex
+var coalescedWrites bool
+
+// the simulated bus survives a reload like a real hardware
+var simAdaptor = simadaptor.NewAdaptor()
+
 // Create will create a static device connection for run
 // before creating, the old gobot robot will be stopped
 // after creating the devices, a new gobot robot will be created and started
 func Create(daemonMode bool, name string, adaptorType AdaptorType, planFile string, recipeFiles RecipeFiles) (runner RailRunner, err error) {
-	if err = Stop(); err != nil {
+	lastGobotMutex.Lock()
+	defer lastGobotMutex.Unlock()
+
+	if err = stop(); err != nil {
 		return
 	}
 
@@ -81,13 +111,20 @@ func Create(daemonMode bool, name string, adaptorType AdaptorType, planFile stri
 		}
 	}
 	fmt.Printf("\n======     Create Delicious Meal     =======")
-	fmt.Printf("\n - Cook gobot adaptor (%s)\n", adaptorType)
 	var adaptor i2cAdaptor
-	if adaptor, err = createAdaptor(adaptorType); err != nil {
-		return
+	var connections []gobot.Connection
+	if book.NeedsAdaptor() {
+		fmt.Printf("\n - Cook gobot adaptor (%s)\n", adaptorType)
+		if adaptor, err = createAdaptor(adaptorType); err != nil {
+			return
+		}
+		connections = append(connections, adaptor)
+	} else {
+		fmt.Printf("\n - Only virtual boards, no gobot adaptor needed\n")
 	}
 	fmt.Printf("\n - Cook APIs\n")
 	boardsAPI := boardsapi.NewBoardsAPI(adaptor)
+	boardsAPI.SetCoalescedWrites(coalescedWrites)
 	deviceAPI := raildevicesapi.NewRailDevicesAPI(boardsAPI)
 	fmt.Printf("\n - Cook boards from recipe list\n")
 	for _, boardRecipe := range book.BoardRecipes {
@@ -114,26 +151,32 @@ func Create(daemonMode bool, name string, adaptorType AdaptorType, planFile stri
 	boardsAPI.ShowAllUsedInputs()
 
 	fmt.Printf("\n====== Start train ride ======\n")
+	cycle := &cycleRunner{boardsAPI: boardsAPI, deviceAPI: deviceAPI}
 
 	if daemonMode {
 		// cyclic call of "Run()" is done by daemon program
 		lastGobot = gobot.NewRobot(name,
-			[]gobot.Connection{adaptor},
+			connections,
 			boardsAPI.GobotDevices(),
 		)
 		// very important for daemon mode
 		lastGobot.AutoRun = false
 	} else {
 		work := func() {
+			// no output should be driven before the boards are verified
+			if err := verifyBoards(boardsAPI); err != nil {
+				fmt.Println(err)
+				return
+			}
 			gobot.Every(10*time.Millisecond, func() {
-				if err := deviceAPI.Run(); err != nil {
+				if err := cycle.Run(); err != nil {
 					fmt.Println(err)
 				}
 			})
 		}
 
 		lastGobot = gobot.NewRobot(name,
-			[]gobot.Connection{adaptor},
+			connections,
 			boardsAPI.GobotDevices(),
 			work,
 		)
@@ -143,21 +186,134 @@ func Create(daemonMode bool, name string, adaptorType AdaptorType, planFile stri
 		return
 	}
 
-	return deviceAPI, nil
+	if daemonMode {
+		if err = verifyBoards(boardsAPI); err != nil {
+			return nil, errwrap.Wrap(err, stop())
+		}
+		lastCycle = cycle
+	}
+
+	return cycle, nil
+}
+
+// Discover searches the bus of the given adaptor for boards, which are not already part of the plan
+// a recipe is returned for each found board
+func Discover(adaptorType AdaptorType, planFile string) (recipes []boardrecipe.Ingredients, err error) {
+	var boardsAPI *boardsapi.BoardsAPI
+	var adaptor i2cAdaptor
+	if boardsAPI, adaptor, err = createBoardsOnly(adaptorType, planFile); err != nil {
+		return
+	}
+	recipes, err = boardsAPI.DiscoverBoards()
+	err = errwrap.Wrap(err, adaptor.Finalize())
+	return
+}
+
+// WriteIdentities writes the identity of all boards with EEPROM of the plan
+func WriteIdentities(adaptorType AdaptorType, planFile string) (err error) {
+	var boardsAPI *boardsapi.BoardsAPI
+	var adaptor i2cAdaptor
+	if boardsAPI, adaptor, err = createBoardsOnly(adaptorType, planFile); err != nil {
+		return
+	}
+	for _, boardRecipe := range boardsAPI.RecipesWithMemory() {
+		fmt.Printf("\n - Write identity of board (%s)\n", boardRecipe)
+		if err = boardsAPI.WriteBoardIdentity(boardRecipe.Name); err != nil {
+			break
+		}
+	}
+	return errwrap.Wrap(err, adaptor.Finalize())
+}
+
+// createBoardsOnly creates and starts all boards of the plan without a gobot robot and rail devices
+func createBoardsOnly(adaptorType AdaptorType, planFile string) (boardsAPI *boardsapi.BoardsAPI, adaptor i2cAdaptor, err error) {
+	if err = Stop(); err != nil {
+		return
+	}
+	var book railplan.CookBook
+	if book, err = railplan.ReadCookBook(planFile); err != nil {
+		return
+	}
+	if adaptor, err = createAdaptor(adaptorType); err != nil {
+		return
+	}
+	if err = adaptor.Connect(); err != nil {
+		return
+	}
+	boardsAPI = boardsapi.NewBoardsAPI(adaptor)
+	for _, boardRecipe := range book.BoardRecipes {
+		if err = boardsAPI.AddBoard(boardRecipe); err != nil {
+			return nil, nil, errwrap.Wrap(err, adaptor.Finalize())
+		}
+	}
+	for _, device := range boardsAPI.GobotDevices() {
+		if err = device.Start(); err != nil {
+			return nil, nil, errwrap.Wrap(err, adaptor.Finalize())
+		}
+	}
+	return
+}
+
+func verifyBoards(boardsAPI *boardsapi.BoardsAPI) (err error) {
+	fmt.Printf("\n - Verify identity of boards\n")
+	var unknownBoards []string
+	if unknownBoards, err = boardsAPI.VerifyBoardIdentities(); err != nil {
+		return
+	}
+	for _, boardID := range unknownBoards {
+		fmt.Printf("\n -- Warning: no identity stored at board '%s'\n", boardID)
+	}
+	return
 }
 
-// Stop stops the gobot robot, when available
+// Stop applies the safe state to all outputs and stops the gobot robot, when available
 func Stop() (err error) {
+	lastGobotMutex.Lock()
+	defer lastGobotMutex.Unlock()
+
+	return stop()
+}
+
+func stop() (err error) {
+	if lastCycle != nil {
+		fmt.Printf("\n------ Apply safe state to outputs ------\n")
+		err = lastCycle.applySafeStates()
+		lastCycle = nil
+	}
 	if lastGobot != nil {
 		if lastGobot.Running() {
 			fmt.Printf("\n------ Stop gobot (%s) ------\n", lastGobot.Name)
-			err = lastGobot.Stop()
+			err = errwrap.Wrap(err, lastGobot.Stop())
 		}
 		lastGobot = nil
 	}
 	return
 }
 
+// Run reads the inputs of all chips only once in this cycle, runs all rail devices and writes pending outputs
+func (c *cycleRunner) Run() (err error) {
+	c.boardsAPI.BeginCycle()
+	err = c.deviceAPI.Run()
+	return errwrap.Wrap(err, c.boardsAPI.EndCycle())
+}
+
+// applySafeStates writes the safe state of all rail devices and all pending outputs afterwards
+func (c *cycleRunner) applySafeStates() (err error) {
+	err = c.deviceAPI.ApplySafeStates()
+	return errwrap.Wrap(err, c.boardsAPI.EndCycle())
+}
+
+// SetCoalescedWrites activates writing of all output changes of a chip once per cycle for the next creation
+// turnouts can be used, when the delay is greater than the cycle time, otherwise the switching pulse would be lost
+func SetCoalescedWrites(coalesce bool) {
+	coalescedWrites = coalesce
+}
+
+// SimAdaptor gets the simulation adaptor, e.g. to simulate inputs or check outputs
+func SimAdaptor() *simadaptor.Adaptor {
+	return simAdaptor
+}
+
 // ParseAdaptorType try get adaptor type from string
 func ParseAdaptorType(adaptorString string) (a AdaptorType, err error) {
 	var ok bool
@@ -16,6 +16,8 @@ func createAdaptor(adaptorType AdaptorType) (adaptor i2cAdaptor, err error) {
 		err = fmt.Errorf("Amd environment not supported by Raspi")
 	case tinkerboardType:
 		err = fmt.Errorf("Arm environment not supported by Tinkerboard")
+	case simType:
+		adaptor = simAdaptor
 	default:
 		err = fmt.Errorf("Unknown type '%d'", adaptorType)
 	}
@@ -17,6 +17,8 @@ func createAdaptor(adaptorType AdaptorType) (adaptor i2cAdaptor, err error) {
 		adaptor = raspi.NewAdaptor()
 	case tinkerboardType:
 		adaptor = tinkerboard.NewAdaptor()
+	case simType:
+		adaptor = simAdaptor
 	default:
 		err = fmt.Errorf("Unknown type '%d'", adaptorType)
 	}
@@ -1,5 +1,14 @@
 package gobrailcreator
 
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
 type BoardsAPIMock struct {
 	values              map[string]uint8
 	callCounterBinMap   int
@@ -63,3 +72,140 @@ func (ba *BoardsAPIMock) SetValue(railDeviceName string, value uint8) (err error
 	ba.callCounterSetValue++
 	return ba.apiSetValueImpl(railDeviceName, value)
 }
+
+func TestCreateWithVirtualBoardNeedsNoAdaptor(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// schemas are referenced relative to the project root
+	wd, _ := os.Getwd()
+	require.Nil(os.Chdir("../../.."))
+	defer os.Chdir(wd)
+	// act
+	runner, err := Create(true, "TestVirtual", unknownType, "./test/data/plans/plan_virtual_test.json", RecipeFiles{})
+	// assert
+	require.Nil(err)
+	require.NotNil(runner)
+	assert.Nil(runner.Run())
+	assert.Nil(Stop())
+}
+
+func TestStopWhileCreate(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	wd, _ := os.Getwd()
+	require.Nil(os.Chdir("../../.."))
+	defer os.Chdir(wd)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	// act
+	go func() {
+		defer wg.Done()
+		Stop()
+	}()
+	runner, err := Create(true, "TestStopWhileCreate", unknownType, "./test/data/plans/plan_virtual_test.json", RecipeFiles{})
+	wg.Wait()
+	// assert
+	require.Nil(err)
+	assert.NotNil(runner)
+	assert.Nil(Stop())
+}
+
+func TestParseAdaptorTypeSim(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	at, err := ParseAdaptorType("Sim")
+	// assert
+	require.Nil(err)
+	assert.Equal(simType, at)
+	assert.Equal("sim", at.String())
+}
+
+func TestCreateWithSimAdaptorRunsEndToEnd(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	wd, _ := os.Getwd()
+	require.Nil(os.Chdir("../../.."))
+	defer os.Chdir(wd)
+	runner, err := Create(true, "TestSim", simType, "./test/data/plans/plan_sim_test.json", RecipeFiles{})
+	require.Nil(err)
+	defer Stop()
+	// act
+	errOff := runner.Run()
+	lampOff := SimAdaptor().Port(0x01) & 0x01
+	// button at pin 4 is low active
+	SimAdaptor().SetInputLevel(0x01, 4, 0)
+	errOn := runner.Run()
+	lampOn := SimAdaptor().Port(0x01) & 0x01
+	SimAdaptor().SetInputLevel(0x01, 4, 1)
+	// assert
+	require.Nil(errOff)
+	require.Nil(errOn)
+	assert.Equal(uint8(0), lampOff)
+	assert.Equal(uint8(1), lampOn)
+}
+
+func TestStopAppliesSafeState(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	wd, _ := os.Getwd()
+	require.Nil(os.Chdir("../../.."))
+	defer os.Chdir(wd)
+	runner, err := Create(true, "TestSimSafe", simType, "./test/data/plans/plan_sim_test.json", RecipeFiles{})
+	require.Nil(err)
+	SimAdaptor().SetInputLevel(0x01, 4, 0)
+	require.Nil(runner.Run())
+	SimAdaptor().SetInputLevel(0x01, 4, 1)
+	lampOn := SimAdaptor().Port(0x01) & 0x01
+	// act
+	errStop := Stop()
+	// assert
+	require.Nil(errStop)
+	assert.Equal(uint8(1), lampOn)
+	assert.Equal(uint8(0), SimAdaptor().Port(0x01)&0x01)
+	assert.Nil(lastCycle)
+	assert.Nil(lastGobot)
+}
+
+func TestDiscoverWithSimAdaptor(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	wd, _ := os.Getwd()
+	require.Nil(os.Chdir("../../.."))
+	defer os.Chdir(wd)
+	// act
+	recipes, err := Discover(simType, "./test/data/plans/plan_sim_test.json")
+	// assert
+	require.Nil(err)
+	// the simulation responds at each address, but address 1 is used by plan
+	require.Equal(0x3F, len(recipes))
+	assert.Equal(uint8(0x00), recipes[0].ChipDevAddr)
+	assert.Equal(uint8(0x02), recipes[1].ChipDevAddr)
+}
+
+func TestCreateWithSwappedBoardGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	wd, _ := os.Getwd()
+	require.Nil(os.Chdir("../../.."))
+	defer os.Chdir(wd)
+	require.Nil(WriteIdentities(simType, "./test/data/plans/plan_sim_test.json"))
+	// act
+	runnerOk, errOk := Create(true, "TestSimOk", simType, "./test/data/plans/plan_sim_test.json", RecipeFiles{})
+	require.Nil(Stop())
+	runner, err := Create(true, "TestSimSwapped", simType, "./test/data/plans/plan_sim_swapped_test.json", RecipeFiles{})
+	// assert
+	require.Nil(errOk)
+	assert.NotNil(runnerOk)
+	require.NotNil(err)
+	assert.Contains(err.Error(), "Board 'B2' (Type2io) at address 0x01 has identity of board 'B1' (Type2io)")
+	assert.Nil(runner)
+	assert.Nil(lastGobot)
+}
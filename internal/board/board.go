@@ -16,20 +16,23 @@ package board
 // + structure for each io at board to configure
 // + set/reset all
 // + set/reset one
-//
-// TODO:
-// - search for main address of board (configmode)
-// - use list of already used i2c device addresses to exclude from search (configmode)
-// - read/write EEPROM at sufficient board or adaptor for "configmode"
+// + read/write identity of board in EEPROM
+// + EEPROM as separate chip with range for general use
 
 import (
+	"errors"
 	"fmt"
 
 	"gobot.io/x/gobot"
 
 	"github.com/gen2thomas/gobrail/internal/boardpin"
+	"github.com/gen2thomas/gobrail/internal/errwrap"
 )
 
+// ErrPinUsage is wrapped by all errors caused by a wrong usage of a board pin, e.g. writing an input or unknown pin
+// in difference to errors of the bus, a repetition will always fail again
+var ErrPinUsage = errors.New("wrong usage of pin")
+
 // DriverOperations is an interface for interact with gobot driver for chip
 type DriverOperations interface {
 	gobot.Driver
@@ -39,6 +42,14 @@ type DriverOperations interface {
 type chip struct {
 	address uint8
 	driver  DriverOperations
+	// optional, when given the GPIO will be read as snapshot once per cycle
+	port *inputPort
+	// optional, when given the GPIO will be written by using a shadow register
+	outputs *outputPort
+	// optional, when given the EEPROM will be read and written by using a shadow
+	memory *eeprom
+	// true, when the PWM frequency is set for servos
+	servoFrequency bool
 }
 
 // PinsMap is a map of all pins on a board, the key is the board pin number
@@ -50,6 +61,8 @@ type Board struct {
 	chips   map[string]*chip
 	pins    PinsMap
 	typeTxt string
+	// analog outputs used for servos
+	servoPins boardpin.PinNumbers
 }
 
 // NewBoard creates a new board with given objects
@@ -57,15 +70,70 @@ func NewBoard(name string, chips map[string]*chip, pins PinsMap, typeTxt string)
 	return &Board{name: name, chips: chips, pins: pins, typeTxt: typeTxt}
 }
 
-// GobotDevices gets all gobot devices of the board
+// GobotDevices gets all gobot devices of the board, a driver shared by some chips (e.g. GPIO and EEPROM) is
+// contained only once
 func (b *Board) GobotDevices() []gobot.Device {
 	var allDevices gobot.Devices
+	contained := make(map[DriverOperations]bool)
 	for _, chip := range b.chips {
-		allDevices = append(allDevices, chip.driver)
+		if !contained[chip.driver] {
+			contained[chip.driver] = true
+			allDevices = append(allDevices, chip.driver)
+		}
 	}
 	return allDevices
 }
 
+// ChipAddresses gets the bus addresses of all chips, e.g. the base address and the offsets of a custom board
+func (b *Board) ChipAddresses() (addresses []uint8) {
+	for _, chip := range b.chips {
+		addresses = append(addresses, chip.address)
+	}
+	return
+}
+
+// ExpireInputs invalidates the input snapshot of all chips, so the next read will get the current values
+// this should be called at begin of each cycle
+func (b *Board) ExpireInputs() {
+	for _, chip := range b.chips {
+		if chip.port != nil {
+			chip.port.expire()
+		}
+	}
+}
+
+// ExpireOutputs invalidates the output shadow of all chips, so the next write will read the register again and a
+// changed value is always written, this should be called after the chip was restarted (e.g. power on reset)
+// also the PWM frequency for servos will be set again, because the driver start does not set it
+func (b *Board) ExpireOutputs() {
+	for _, chip := range b.chips {
+		if chip.outputs != nil {
+			chip.outputs.expire()
+		}
+		chip.servoFrequency = false
+	}
+}
+
+// SetCoalescedWrites activates or deactivates coalescing of GPIO writes, when active all changes of a chip will be
+// written by calling "FlushOutputs"
+func (b *Board) SetCoalescedWrites(coalesce bool) {
+	for _, chip := range b.chips {
+		if chip.outputs != nil {
+			chip.outputs.coalesce = coalesce
+		}
+	}
+}
+
+// FlushOutputs writes all pending GPIO changes of all chips, this should be called at end of each cycle
+func (b *Board) FlushOutputs() (err error) {
+	for _, chip := range b.chips {
+		if chip.outputs != nil {
+			err = errwrap.Wrap(err, chip.outputs.flush())
+		}
+	}
+	return
+}
+
 // GetPinNumbers gets all pins of board
 func (b *Board) GetPinNumbers() (pinNumbers boardpin.PinNumbers) {
 	pinNumbers = make(boardpin.PinNumbers)
@@ -101,12 +169,18 @@ func (b *Board) WriteValue(boardPinNr uint8, value uint8) (err error) {
 		err = b.writeGPIO(bPin, getNegatedBinaryValue(value))
 	case boardpin.NBinaryW:
 		err = b.writeGPIO(bPin, getNegatedBinaryValue(value))
+	case boardpin.Analog, boardpin.AnalogW:
+		if _, ok := b.servoPins[boardPinNr]; ok {
+			err = b.writeServo(bPin, value)
+		} else {
+			err = b.writeAnalog(bPin, value)
+		}
 	case boardpin.Memory:
 		err = b.writeEEPROM(bPin, value)
 	case boardpin.MemoryW:
 		err = b.writeEEPROM(bPin, value)
 	default:
-		err = fmt.Errorf("Pin %d with type %v not allowed to set with value %d", boardPinNr, bPin.PinType, value)
+		err = fmt.Errorf("Pin %d with type %v not allowed to set with value %d, %w", boardPinNr, bPin.PinType, value, ErrPinUsage)
 	}
 	return
 }
@@ -133,7 +207,7 @@ func (b *Board) ReadValue(boardPinNr uint8) (value uint8, err error) {
 	case boardpin.MemoryR:
 		value, err = b.readEEPROM(bPin)
 	default:
-		err = fmt.Errorf("Pin %d with type %v not allowed to read value", boardPinNr, bPin.PinType)
+		err = fmt.Errorf("Pin %d with type %v not allowed to read value, %w", boardPinNr, bPin.PinType, ErrPinUsage)
 	}
 	return
 }
@@ -158,7 +232,7 @@ func (b *Board) String() string {
 func (b *Board) getBoardPin(boardPinNr uint8) (boardPin *boardpin.Pin, err error) {
 	var ok bool
 	if boardPin, ok = b.pins[boardPinNr]; !ok {
-		err = fmt.Errorf("Pin %d not there in board %s", boardPinNr, b.name)
+		err = fmt.Errorf("Pin %d not there in board %s, %w", boardPinNr, b.name, ErrPinUsage)
 	}
 	return
 }
@@ -167,13 +241,27 @@ func (b *Board) getDriver(boardPin *boardpin.Pin) (driver DriverOperations, err
 	var ok bool
 	var chip *chip
 	if chip, ok = b.chips[boardPin.ChipID]; !ok {
-		err = fmt.Errorf("Driver for %s not there in board %s", boardPin.ChipID, b.name)
+		err = fmt.Errorf("Driver for %s not there in board %s, %w", boardPin.ChipID, b.name, ErrPinUsage)
 		return
 	}
 	driver = chip.driver
 	return
 }
 
+func (b *Board) getOutputPort(boardPin *boardpin.Pin) *outputPort {
+	if chip, ok := b.chips[boardPin.ChipID]; ok {
+		return chip.outputs
+	}
+	return nil
+}
+
+func (b *Board) getInputPort(boardPin *boardpin.Pin) *inputPort {
+	if chip, ok := b.chips[boardPin.ChipID]; ok {
+		return chip.port
+	}
+	return nil
+}
+
 func getNegatedBinaryValue(value uint8) uint8 {
 	if value > 0 {
 		return 0
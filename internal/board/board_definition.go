@@ -0,0 +1,85 @@
+package board
+
+// Implementation for circuit boards described by a board definition
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : the same chip implementations as used for the fixed board types
+//
+// A board definition contains any count of chips, each with a driver kind and an address offset, and the pins of
+// the board. The address of each chip is the address of the board recipe plus the address offset of the chip.
+// For the MCP23017 all pins with a read only pin type are configured as input.
+// The memory pins of a PCA9501 are mapped to a separate chip for the EEPROM with the ID of the chip plus ".EEPROM".
+//
+// Functions:
+// + create a board with all chips and pins of the definition
+//
+
+import (
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/boarddefinition"
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+// NewBoardFromDefinition creates a new board with all chips and pins of the given definition.
+func NewBoardFromDefinition(adaptor i2c.Connector, address uint8, name string,
+	definition boarddefinition.Definition) (*Board, error) {
+	if err := definition.Verify(); err != nil {
+		return nil, err
+	}
+
+	pins := make(PinsMap)
+	for _, pin := range definition.Pins {
+		pins[pin.BoardPinNr] = &boardpin.Pin{
+			ChipID:    pin.ChipID,
+			ChipPinNr: pin.ChipPinNr,
+			PinType:   boardpin.PinTypeMap[pin.PinType],
+			MinVal:    pin.MinVal,
+			MaxVal:    pin.MaxVal,
+		}
+	}
+
+	chips := make(map[string]*chip)
+	for _, chipDef := range definition.Chips {
+		chipAddress := address + chipDef.AddressOffset
+		switch boarddefinition.DriverMap[chipDef.Driver] {
+		case boarddefinition.PCA9501:
+			chips[chipDef.ID] = newPCA9501Chip(adaptor, chipAddress, pins.inputMask(chipDef.ID))
+			if pins.moveMemoryPins(chipDef.ID, chipDef.ID+eepromChipIDSuffix) {
+				chips[chipDef.ID+eepromChipIDSuffix] = newEEPROMChip(chips[chipDef.ID].driver, chipAddress|0x40)
+			}
+		case boarddefinition.PCF8574:
+			chips[chipDef.ID] = newPCA9501Chip(adaptor, chipAddress, pins.inputMask(chipDef.ID))
+		case boarddefinition.MCP23017:
+			driver := newMCP23017Driver(adaptor, chipAddress, pins.chipPinNumbersOfType(chipDef.ID, readPinTypes))
+			chips[chipDef.ID] = newMCP23017Chip(driver, adaptor, chipAddress)
+		case boarddefinition.PCA9685:
+			chips[chipDef.ID] = newPCA9685Chip(adaptor, chipAddress)
+		}
+	}
+
+	return NewBoard(name, chips, pins, definition.Type), nil
+}
+
+// moveMemoryPins assigns all memory pins of the given chip to the EEPROM chip, returns true when any pin is moved
+func (pm PinsMap) moveMemoryPins(chipID string, eepromChipID string) (moved bool) {
+	for _, bPin := range pm {
+		if bPin.ChipID == chipID && bPin.PinTypeIsOneOf(memoryPinTypes) {
+			bPin.ChipID = eepromChipID
+			moved = true
+		}
+	}
+	return
+}
+
+// chipPinNumbersOfType gets the chip pin numbers of all pins of the given chip and types
+func (pm PinsMap) chipPinNumbersOfType(chipID string, pinTypes []boardpin.PinType) (chipPinNumbers []uint8) {
+	for _, bPin := range pm {
+		if bPin.ChipID == chipID && bPin.PinTypeIsOneOf(pinTypes) {
+			chipPinNumbers = append(chipPinNumbers, bPin.ChipPinNr)
+		}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boarddefinition"
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+var carrierDefinition = boarddefinition.Definition{
+	Type: "Carrier",
+	Chips: []boarddefinition.Chip{
+		{ID: "GPIO", Driver: "PCA9501", AddressOffset: 0},
+		{ID: "Ports", Driver: "MCP23017", AddressOffset: 0x1C},
+		{ID: "PWM", Driver: "PCA9685", AddressOffset: 0x3C},
+	},
+	Pins: []boarddefinition.Pin{
+		{BoardPinNr: 0, ChipID: "GPIO", ChipPinNr: 0, PinType: "NBinaryR"},
+		{BoardPinNr: 1, ChipID: "GPIO", ChipPinNr: 5, PinType: "Binary"},
+		{BoardPinNr: 2, ChipID: "GPIO", ChipPinNr: 0x10, PinType: "Memory"},
+		{BoardPinNr: 10, ChipID: "Ports", ChipPinNr: 3, PinType: "BinaryR"},
+		{BoardPinNr: 11, ChipID: "Ports", ChipPinNr: 12, PinType: "NBinaryW"},
+		{BoardPinNr: 20, ChipID: "PWM", ChipPinNr: 15, PinType: "AnalogW", MinVal: 10, MaxVal: 200},
+	},
+}
+
+func TestNewBoardFromDefinition(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	boardDef, err := NewBoardFromDefinition(new(adaptorMock), 0x04, "TestNewBoardFromDefinition", carrierDefinition)
+	// assert
+	require.Nil(err)
+	require.NotNil(boardDef)
+	assert.Equal("TestNewBoardFromDefinition", boardDef.name)
+	assert.Equal("Carrier", boardDef.typeTxt)
+	assert.Equal(6, len(boardDef.GetPinNumbers()))
+	assert.Equal(3, len(boardDef.GobotDevices()))
+	assert.Equal(uint8(0x04), boardDef.chips["GPIO"].address)
+	assert.Equal(uint8(0x20), boardDef.chips["Ports"].address)
+	assert.Equal(uint8(0x40), boardDef.chips["PWM"].address)
+	assert.Equal(boardpin.NBinaryR, boardDef.pins[0].PinType)
+	assert.Equal(boardpin.NBinaryW, boardDef.pins[11].PinType)
+	assert.Equal(uint8(200), boardDef.pins[20].MaxVal)
+	assert.True(boardDef.HasMemory())
+	assert.Equal("GPIO.EEPROM", boardDef.pins[2].ChipID)
+	assert.Equal(uint8(0x44), boardDef.chips["GPIO.EEPROM"].address)
+	assert.Equal(map[uint8]bool{3: true}, boardDef.chips["Ports"].driver.(*mcp23017Driver).inputPins)
+	assert.Nil(boardDef.chips["PWM"].port)
+}
+
+func TestNewBoardFromDefinitionNotValidGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	definition := boarddefinition.Definition{
+		Type:  "Carrier",
+		Chips: []boarddefinition.Chip{{ID: "GPIO", Driver: "PCA9501"}},
+		Pins:  []boarddefinition.Pin{{BoardPinNr: 0, ChipID: "PWM", PinType: "AnalogW"}},
+	}
+	// act
+	boardDef, err := NewBoardFromDefinition(new(adaptorMock), 0x04, "TestNewBoardFromDefinition", definition)
+	// assert
+	require.NotNil(err)
+	assert.Nil(boardDef)
+	assert.Contains(err.Error(), "chip 'PWM' of board pin 0 is not defined")
+}
+
+func TestChipPinNumbersOfType(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	pins := PinsMap{
+		0: {ChipID: "A", ChipPinNr: 4, PinType: boardpin.BinaryR},
+		1: {ChipID: "A", ChipPinNr: 5, PinType: boardpin.BinaryW},
+		2: {ChipID: "B", ChipPinNr: 6, PinType: boardpin.BinaryR},
+	}
+	// act
+	chipPinNumbers := pins.chipPinNumbersOfType("A", readPinTypes)
+	// assert
+	assert.Equal([]uint8{4}, chipPinNumbers)
+}
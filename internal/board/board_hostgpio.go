@@ -0,0 +1,140 @@
+package board
+
+// Implementation for the GPIO header of the host (e.g. Raspberry Pi, Tinkerboard) as a board "HostGPIO"
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : digital read/write of the gobot adaptor (arm) or a stand-in in memory (amd64)
+//
+// The board pins 0..16 are mapped to the header pins, which are not used by I2C, SPI, UART or the ID EEPROM.
+// The header pin names are equal for Raspberry Pi and Tinkerboard, see "hostHeaderPins".
+// All pins are outputs (high active), pins in the given list will be used as inputs (negotiated read, a button
+// switches to ground and an external pull up resistor is needed) instead.
+//
+// Functions:
+// + read/write GPIO at header of host
+//
+
+import (
+	"fmt"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+const chipIDHostGPIO = "Host.GPIO"
+
+// hostHeaderPins are the names of the usable header pins, the index is the chip pin number
+var hostHeaderPins = []string{"7", "11", "12", "13", "15", "16", "18", "22", "29", "31", "32", "33", "35", "36", "37",
+	"38", "40"}
+
+// hostHeader is the digital read/write interface of the adaptor, e.g. gobot raspi or tinkerboard
+type hostHeader interface {
+	DigitalRead(pin string) (val int, err error)
+	DigitalWrite(pin string, val byte) (err error)
+}
+
+// hostGPIODriver maps the chip pin numbers to the header pin names for the commands
+type hostGPIODriver struct {
+	name       string
+	header     hostHeader
+	connection gobot.Connection
+	gobot.Commander
+}
+
+// NewBoardHostGPIO creates a new board with the GPIO header of the host and 17 outputs. All pins in the given list
+// will be used as inputs (negotiated read) instead.
+func NewBoardHostGPIO(adaptor i2c.Connector, name string, inputPins []uint8) (*Board, error) {
+	header, err := newHostHeader(adaptor)
+	if err != nil {
+		return nil, err
+	}
+	driver := newHostGPIODriver(header)
+	chips := map[string]*chip{chipIDHostGPIO: {driver: driver}}
+
+	isInput := make(map[uint8]bool)
+	for _, pin := range inputPins {
+		isInput[pin] = true
+	}
+	pins := make(PinsMap)
+	for chipPinNr := range hostHeaderPins {
+		pinType := boardpin.BinaryW
+		if isInput[uint8(chipPinNr)] {
+			pinType = boardpin.NBinaryR
+		}
+		pins[uint8(chipPinNr)] = &boardpin.Pin{ChipID: chipIDHostGPIO, ChipPinNr: uint8(chipPinNr), PinType: pinType}
+	}
+
+	return NewBoard(name, chips, pins, "HostGPIO"), nil
+}
+
+func newHostGPIODriver(header hostHeader) *hostGPIODriver {
+	d := &hostGPIODriver{
+		name:      gobot.DefaultName("HostGPIO"),
+		header:    header,
+		Commander: gobot.NewCommander(),
+	}
+	if connection, ok := header.(gobot.Connection); ok {
+		d.connection = connection
+	}
+
+	d.AddCommand("WriteGPIO", func(params map[string]interface{}) interface{} {
+		err := d.writeGPIO(params["pin"].(uint8), params["val"].(uint8))
+		return map[string]interface{}{"err": err}
+	})
+
+	d.AddCommand("ReadGPIO", func(params map[string]interface{}) interface{} {
+		val, err := d.readGPIO(params["pin"].(uint8))
+		return map[string]interface{}{"val": val, "err": err}
+	})
+
+	return d
+}
+
+// Name returns the name of the host GPIO driver
+func (d *hostGPIODriver) Name() string { return d.name }
+
+// SetName sets the name of the host GPIO driver
+func (d *hostGPIODriver) SetName(name string) { d.name = name }
+
+// Start does nothing, the direction of the pin is set by the adaptor on each read or write
+func (d *hostGPIODriver) Start() (err error) { return }
+
+// Halt does nothing, the adaptor releases the pins on finalize
+func (d *hostGPIODriver) Halt() (err error) { return }
+
+// Connection returns the adaptor, or nil for the stand-in
+func (d *hostGPIODriver) Connection() gobot.Connection { return d.connection }
+
+func (d *hostGPIODriver) writeGPIO(chipPinNr uint8, val uint8) (err error) {
+	var headerPin string
+	if headerPin, err = getHostHeaderPin(chipPinNr); err != nil {
+		return
+	}
+	return d.header.DigitalWrite(headerPin, val)
+}
+
+func (d *hostGPIODriver) readGPIO(chipPinNr uint8) (val uint8, err error) {
+	var headerPin string
+	if headerPin, err = getHostHeaderPin(chipPinNr); err != nil {
+		return
+	}
+	var level int
+	if level, err = d.header.DigitalRead(headerPin); err != nil {
+		return
+	}
+	if level > 0 {
+		val = 1
+	}
+	return
+}
+
+func getHostHeaderPin(chipPinNr uint8) (headerPin string, err error) {
+	if int(chipPinNr) >= len(hostHeaderPins) {
+		return "", fmt.Errorf("Chip pin %d is not mapped to a header pin of host, %w", chipPinNr, ErrPinUsage)
+	}
+	return hostHeaderPins[chipPinNr], nil
+}
@@ -0,0 +1,44 @@
+package board
+
+// special implementation part for amd64 (x86) targets, there is no GPIO header at the host, so a stand-in is used
+// the stand-in holds the levels of all header pins in memory, an input can be simulated by "DigitalWrite"
+
+import (
+	"fmt"
+	"sync"
+
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+type hostHeaderStandIn struct {
+	levels map[string]byte
+	mutex  *sync.Mutex
+}
+
+func newHostHeader(adaptor i2c.Connector) (header hostHeader, err error) {
+	return &hostHeaderStandIn{levels: make(map[string]byte), mutex: &sync.Mutex{}}, nil
+}
+
+// DigitalRead gets the last written level of the header pin
+func (h *hostHeaderStandIn) DigitalRead(pin string) (val int, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.levels[pin]; !ok {
+		// like an input with pull up
+		return 1, nil
+	}
+	return int(h.levels[pin]), nil
+}
+
+// DigitalWrite sets the level of the header pin
+func (h *hostHeaderStandIn) DigitalWrite(pin string, val byte) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if val > 1 {
+		return fmt.Errorf("The level %d is not valid for header pin %s", val, pin)
+	}
+	h.levels[pin] = val
+	return
+}
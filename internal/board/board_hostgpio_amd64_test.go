@@ -0,0 +1,74 @@
+package board
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+func TestNewBoardHostGPIO(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	boardhost, err := NewBoardHostGPIO(nil, "TestNewBoardHostGPIO", []uint8{3, 16})
+	// assert
+	require.Nil(err)
+	require.NotNil(boardhost)
+	assert.Equal("TestNewBoardHostGPIO", boardhost.name)
+	assert.Equal(17, len(boardhost.GetPinNumbers()))
+	assert.Equal(boardpin.BinaryW, boardhost.pins[0].PinType)
+	assert.Equal(boardpin.NBinaryR, boardhost.pins[3].PinType)
+	assert.Equal(boardpin.NBinaryR, boardhost.pins[16].PinType)
+	assert.False(boardhost.HasMemory())
+	assert.Nil(boardhost.GobotDevices()[0].Connection())
+}
+
+func TestHostGPIOWriteReadValue(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardhost, _ := NewBoardHostGPIO(nil, "TestHostGPIOWriteReadValue", []uint8{2})
+	header := boardhost.chips[chipIDHostGPIO].driver.(*hostGPIODriver).header
+	// act
+	errWrite := boardhost.WriteValue(1, 1)
+	valueReleased, errReleased := boardhost.ReadValue(2)
+	require.Nil(header.DigitalWrite("12", 0))
+	valuePressed, errPressed := boardhost.ReadValue(2)
+	// assert
+	require.Nil(errWrite)
+	require.Nil(errReleased)
+	require.Nil(errPressed)
+	level, _ := header.DigitalRead("11")
+	assert.Equal(1, level)
+	assert.Equal(uint8(0), valueReleased)
+	assert.Equal(uint8(1), valuePressed)
+}
+
+func TestHostGPIOChipPinNotMappedGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	driver := newHostGPIODriver(&hostHeaderStandIn{levels: make(map[string]byte), mutex: &sync.Mutex{}})
+	// act
+	_, err := driver.readGPIO(17)
+	// assert
+	require.NotNil(err)
+	assert.ErrorIs(err, ErrPinUsage)
+	assert.Contains(err.Error(), "Chip pin 17 is not mapped")
+}
+
+func TestGetHostHeaderPin(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act
+	first, _ := getHostHeaderPin(0)
+	last, _ := getHostHeaderPin(16)
+	// assert
+	assert.Equal("7", first)
+	assert.Equal("40", last)
+}
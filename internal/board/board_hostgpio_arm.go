@@ -0,0 +1,17 @@
+package board
+
+// special implementation part for arm targets, the header of the host is accessed by the gobot adaptor
+
+import (
+	"fmt"
+
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+func newHostHeader(adaptor i2c.Connector) (header hostHeader, err error) {
+	var ok bool
+	if header, ok = adaptor.(hostHeader); !ok {
+		err = fmt.Errorf("The adaptor provides no GPIO header")
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package board
+
+// Implementation for circuit boards with one I2C chip MCP23017
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : some functions from gobot-i2c (MCP23017)
+//
+// 23017:
+// - 16 GPIO at two ports A (board pin 0..7) and B (board pin 8..15)
+// - direction is configured per pin, inputs are used with internal pull up and negotiated read
+// - outputs can source and sink current, therefore outputs are high active
+// - address range 0x20..0x27
+//
+// Functions:
+// + read/write GPIO at board
+// + set direction and pull up of each pin on start
+//
+
+import (
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+const chipIDMCP23017 = "MCP23017.GPIO"
+
+// register GPIOA for bank 0, the next register is GPIOB
+const mcp23017RegGPIO = 0x12
+
+// mcp23017Driver maps the chip pins 0..15 to the pins 0..7 at port A and B for the commands
+// and configures the direction of all pins on start
+type mcp23017Driver struct {
+	*i2c.MCP23017Driver
+	gobot.Commander
+	inputPins map[uint8]bool
+}
+
+// NewBoardMCP23017 creates a new board with a MCP23017 and 16 outputs. All pins in the given list will be used as
+// inputs (negotiated read with pull up) instead.
+func NewBoardMCP23017(adaptor i2c.Connector, address uint8, name string, inputPins []uint8) *Board {
+	driver := newMCP23017Driver(adaptor, address, inputPins)
+	chips := map[string]*chip{chipIDMCP23017: newMCP23017Chip(driver, adaptor, address)}
+
+	pins := make(PinsMap)
+	for chipPinNr := uint8(0); chipPinNr < 16; chipPinNr++ {
+		pinType := boardpin.BinaryW
+		if driver.inputPins[chipPinNr] {
+			pinType = boardpin.NBinaryR
+		}
+		pins[chipPinNr] = &boardpin.Pin{ChipID: chipIDMCP23017, ChipPinNr: chipPinNr, PinType: pinType}
+	}
+
+	return NewBoard(name, chips, pins, "MCP23017")
+}
+
+// newMCP23017Chip creates a chip for the given driver, the GPIO of both ports is read and written at once
+func newMCP23017Chip(driver *mcp23017Driver, adaptor i2c.Connector, address uint8) *chip {
+	chipCon := newChipConnection(adaptor, address)
+	return &chip{
+		address: address,
+		driver:  driver,
+		port:    newRegisterPort(chipCon, mcp23017RegGPIO),
+		outputs: newRegisterOutputPort(chipCon, mcp23017RegOLAT),
+	}
+}
+
+func newMCP23017Driver(adaptor i2c.Connector, address uint8, inputPins []uint8) *mcp23017Driver {
+	d := &mcp23017Driver{
+		MCP23017Driver: i2c.NewMCP23017Driver(adaptor, i2c.WithAddress(int(address))),
+		Commander:      gobot.NewCommander(),
+		inputPins:      make(map[uint8]bool),
+	}
+	for _, pin := range inputPins {
+		d.inputPins[pin] = true
+	}
+
+	d.AddCommand("WriteGPIO", func(params map[string]interface{}) interface{} {
+		pin, port := splitMCP23017Pin(params["pin"].(uint8))
+		err := d.WriteGPIO(pin, params["val"].(uint8), port)
+		return map[string]interface{}{"err": err}
+	})
+
+	d.AddCommand("ReadGPIO", func(params map[string]interface{}) interface{} {
+		pin, port := splitMCP23017Pin(params["pin"].(uint8))
+		val, err := d.ReadGPIO(pin, port)
+		return map[string]interface{}{"val": val, "err": err}
+	})
+
+	return d
+}
+
+// Start writes the device configuration and sets the direction of all pins
+func (d *mcp23017Driver) Start() (err error) {
+	if err = d.MCP23017Driver.Start(); err != nil {
+		return
+	}
+	for chipPinNr := uint8(0); chipPinNr < 16; chipPinNr++ {
+		pin, port := splitMCP23017Pin(chipPinNr)
+		if !d.inputPins[chipPinNr] {
+			if err = d.PinMode(pin, 0, port); err != nil {
+				return
+			}
+			continue
+		}
+		if err = d.PinMode(pin, 1, port); err != nil {
+			return
+		}
+		if err = d.SetPullUp(pin, 1, port); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func splitMCP23017Pin(chipPinNr uint8) (pin uint8, port string) {
+	if chipPinNr < 8 {
+		return chipPinNr, "A"
+	}
+	return chipPinNr - 8, "B"
+}
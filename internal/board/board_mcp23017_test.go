@@ -0,0 +1,136 @@
+package board
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+// register addresses for bank 0
+const (
+	mcpIODIRA = 0x00
+	mcpIODIRB = 0x01
+	mcpGPPUB  = 0x0D
+	mcpGPIOB  = 0x13
+	mcpOLATA  = 0x14
+)
+
+type mcpConnectorMock struct {
+	con *mcpConnectionMock
+}
+
+type mcpConnectionMock struct {
+	regs [0x16]uint8
+}
+
+func newMcpConnectorMock() *mcpConnectorMock {
+	con := &mcpConnectionMock{}
+	// after power on all pins are inputs
+	con.regs[mcpIODIRA] = 0xFF
+	con.regs[mcpIODIRB] = 0xFF
+	return &mcpConnectorMock{con: con}
+}
+
+func TestNewBoardMCP23017(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	boardmcp := NewBoardMCP23017(new(adaptorMock), 0x20, "TestNewBoardMCP23017", []uint8{2, 9})
+	// assert
+	require.NotNil(boardmcp)
+	assert.Equal("TestNewBoardMCP23017", boardmcp.name)
+	assert.Equal(16, len(boardmcp.GetPinNumbers()))
+	assert.Equal(boardpin.BinaryW, boardmcp.pins[0].PinType)
+	assert.Equal(boardpin.NBinaryR, boardmcp.pins[2].PinType)
+	assert.Equal(boardpin.NBinaryR, boardmcp.pins[9].PinType)
+	assert.Equal(boardpin.BinaryW, boardmcp.pins[15].PinType)
+}
+
+func TestSplitMCP23017Pin(t *testing.T) {
+	var splitTests = map[uint8]struct {
+		pin  uint8
+		port string
+	}{
+		0:  {pin: 0, port: "A"},
+		7:  {pin: 7, port: "A"},
+		8:  {pin: 0, port: "B"},
+		15: {pin: 7, port: "B"},
+	}
+	for chipPinNr, st := range splitTests {
+		t.Run(fmt.Sprintf("ChipPin%d", chipPinNr), func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			// act
+			pin, port := splitMCP23017Pin(chipPinNr)
+			// assert
+			assert.Equal(st.pin, pin)
+			assert.Equal(st.port, port)
+		})
+	}
+}
+
+func TestMCP23017StartSetsDirectionAndPullUp(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	connector := newMcpConnectorMock()
+	boardmcp := NewBoardMCP23017(connector, 0x20, "TestMCP23017Start", []uint8{1, 9})
+	// act
+	err := boardmcp.GobotDevices()[0].Start()
+	// assert
+	require.Nil(err)
+	assert.Equal(uint8(0x02), connector.con.regs[mcpIODIRA])
+	assert.Equal(uint8(0x02), connector.con.regs[mcpIODIRB])
+	assert.Equal(uint8(0x02), connector.con.regs[mcpGPPUB])
+}
+
+func TestMCP23017WriteReadValue(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	connector := newMcpConnectorMock()
+	boardmcp := NewBoardMCP23017(connector, 0x20, "TestMCP23017WriteReadValue", []uint8{9})
+	require.Nil(boardmcp.GobotDevices()[0].Start())
+	connector.con.regs[mcpGPIOB] = 0xFD
+	// act
+	errWrite := boardmcp.WriteValue(3, 1)
+	val, errRead := boardmcp.ReadValue(9)
+	// assert
+	require.Nil(errWrite)
+	require.Nil(errRead)
+	assert.Equal(uint8(0x08), connector.con.regs[mcpOLATA])
+	assert.Equal(uint8(1), val)
+}
+
+func (a *mcpConnectorMock) GetConnection(address int, bus int) (device i2c.Connection, err error) {
+	return a.con, nil
+}
+func (a *mcpConnectorMock) GetDefaultBus() int { return 0 }
+
+func (c *mcpConnectionMock) Read(b []byte) (n int, err error)  { return }
+func (c *mcpConnectionMock) Write(b []byte) (n int, err error) { return len(b), nil }
+func (c *mcpConnectionMock) Close() (err error)                { return }
+func (c *mcpConnectionMock) ReadByte() (val byte, err error)   { return }
+func (c *mcpConnectionMock) ReadByteData(reg uint8) (val uint8, err error) {
+	return c.regs[reg], nil
+}
+func (c *mcpConnectionMock) ReadWordData(reg uint8) (val uint16, err error) {
+	return uint16(c.regs[reg+1])<<8 | uint16(c.regs[reg]), nil
+}
+func (c *mcpConnectionMock) WriteByte(val byte) (err error) { return }
+func (c *mcpConnectionMock) WriteByteData(reg uint8, val uint8) (err error) {
+	c.regs[reg] = val
+	return
+}
+func (c *mcpConnectionMock) WriteWordData(reg uint8, val uint16) (err error) {
+	c.regs[reg] = uint8(val)
+	c.regs[reg+1] = uint8(val >> 8)
+	return
+}
+func (c *mcpConnectionMock) WriteBlockData(reg uint8, b []byte) (err error) { return }
@@ -0,0 +1,162 @@
+package board
+
+// Implementation for circuit boards with one I2C chip PCA9685
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : some functions from gobot-i2c (PCA9685)
+//
+// 9685:
+// - 16 PWM outputs with 12 bit resolution, the value 0..255 is scaled to the duty cycle 0..4095
+// - the value is limited to the range given by MinVal and MaxVal of the board pin
+// - address range 0x40..0x7F, 0x70 is the "all call" address after power on
+// - an output in servo mode gets a pulse width of 0.5ms..2.5ms for the value 0..255, the PWM frequency of the chip is
+//   set to 50Hz at first servo write, so all outputs of this chip are used with 50Hz afterwards
+// - after a restart of the chip (e.g. power on reset) the frequency is set again at next servo write
+//
+// Functions:
+// + write PWM at board
+// + write servo position at board
+//
+
+import (
+	"fmt"
+	"strconv"
+
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+const chipIDPCA9685 = "PCA9685.PWM"
+
+const (
+	servoFrequency = 50
+	// pulse width 0.5ms and 2.5ms at 50Hz with 12 bit resolution (20ms = 4096)
+	servoPulseMin = 102
+	servoPulseMax = 512
+)
+
+// this is the io configuration of PCA9685
+var boardPinsPCA9685 = PinsMap{
+	0:  {ChipID: chipIDPCA9685, ChipPinNr: 0, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	1:  {ChipID: chipIDPCA9685, ChipPinNr: 1, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	2:  {ChipID: chipIDPCA9685, ChipPinNr: 2, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	3:  {ChipID: chipIDPCA9685, ChipPinNr: 3, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	4:  {ChipID: chipIDPCA9685, ChipPinNr: 4, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	5:  {ChipID: chipIDPCA9685, ChipPinNr: 5, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	6:  {ChipID: chipIDPCA9685, ChipPinNr: 6, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	7:  {ChipID: chipIDPCA9685, ChipPinNr: 7, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	8:  {ChipID: chipIDPCA9685, ChipPinNr: 8, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	9:  {ChipID: chipIDPCA9685, ChipPinNr: 9, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	10: {ChipID: chipIDPCA9685, ChipPinNr: 10, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	11: {ChipID: chipIDPCA9685, ChipPinNr: 11, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	12: {ChipID: chipIDPCA9685, ChipPinNr: 12, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	13: {ChipID: chipIDPCA9685, ChipPinNr: 13, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	14: {ChipID: chipIDPCA9685, ChipPinNr: 14, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+	15: {ChipID: chipIDPCA9685, ChipPinNr: 15, PinType: boardpin.AnalogW, MinVal: 0, MaxVal: 255},
+}
+
+// NewBoardPCA9685 creates a new board with a PCA9685 and 16 PWM outputs (analog write).
+func NewBoardPCA9685(adaptor i2c.Connector, address uint8, name string) *Board {
+	chips := map[string]*chip{chipIDPCA9685: newPCA9685Chip(adaptor, address)}
+
+	return NewBoard(name, chips, boardPinsPCA9685, "PCA9685")
+}
+
+// newPCA9685Chip creates a chip with a PCA9685 driver, the PWM outputs are written directly
+func newPCA9685Chip(adaptor i2c.Connector, address uint8) *chip {
+	return &chip{
+		address: address,
+		driver:  i2c.NewPCA9685Driver(adaptor, i2c.WithAddress(int(address))),
+	}
+}
+
+func (b *Board) writeAnalog(bPin *boardpin.Pin, val uint8) (err error) {
+	var driver DriverOperations
+	if driver, err = b.getDriver(bPin); err != nil {
+		return
+	}
+	if val < bPin.MinVal {
+		val = bPin.MinVal
+	}
+	if val > bPin.MaxVal {
+		val = bPin.MaxVal
+	}
+	var params = map[string]interface{}{
+		"pin": strconv.Itoa(int(bPin.ChipPinNr)),
+		"val": strconv.Itoa(int(val)),
+	}
+	return commandError(driver.Command("PwmWrite")(params))
+}
+
+// SetServoMode activates or deactivates the servo mode of an analog output, in servo mode the value is written as
+// pulse width for a servo
+func (b *Board) SetServoMode(boardPinNr uint8, servo bool) (err error) {
+	if !servo {
+		delete(b.servoPins, boardPinNr)
+		return
+	}
+	var bPin *boardpin.Pin
+	if bPin, err = b.getBoardPin(boardPinNr); err != nil {
+		return
+	}
+	var driver DriverOperations
+	if driver, err = b.getDriver(bPin); err != nil {
+		return
+	}
+	if !bPin.PinTypeIsOneOf([]boardpin.PinType{boardpin.Analog, boardpin.AnalogW}) || driver.Command("SetPWM") == nil {
+		return fmt.Errorf("Pin %d of board %s can't be used for a servo, %w", boardPinNr, b.name, ErrPinUsage)
+	}
+	if b.servoPins == nil {
+		b.servoPins = make(boardpin.PinNumbers)
+	}
+	b.servoPins[boardPinNr] = struct{}{}
+	return
+}
+
+// writeServo writes the value as pulse width with 12 bit resolution, the frequency of the chip is set before first use
+func (b *Board) writeServo(bPin *boardpin.Pin, val uint8) (err error) {
+	var driver DriverOperations
+	if driver, err = b.getDriver(bPin); err != nil {
+		return
+	}
+	if val < bPin.MinVal {
+		val = bPin.MinVal
+	}
+	if val > bPin.MaxVal {
+		val = bPin.MaxVal
+	}
+	servoChip := b.chips[bPin.ChipID]
+	if !servoChip.servoFrequency {
+		freqParams := map[string]interface{}{"freq": strconv.Itoa(servoFrequency)}
+		if err = commandError(driver.Command("SetPWMFreq")(freqParams)); err != nil {
+			return
+		}
+		servoChip.servoFrequency = true
+	}
+	var params = map[string]interface{}{
+		"channel": strconv.Itoa(int(bPin.ChipPinNr)),
+		"on":      "0",
+		"off":     strconv.Itoa(servoPulseMin + int(val)*(servoPulseMax-servoPulseMin)/255),
+	}
+	return commandError(driver.Command("SetPWM")(params))
+}
+
+// commandError gets the error of a command result, some drivers return the error directly, others in a map
+func commandError(result interface{}) (err error) {
+	switch res := result.(type) {
+	case nil:
+		return
+	case error:
+		return res
+	case map[string]interface{}:
+		if res["err"] != nil {
+			return res["err"].(error)
+		}
+		return
+	default:
+		return fmt.Errorf("Unknown command result %v", result)
+	}
+}
@@ -0,0 +1,226 @@
+package board
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+type pwmDriverMock struct {
+	deviceMock
+	params map[string]interface{}
+	// names of all called commands in order
+	commands []string
+	err      error
+}
+
+func TestNewBoardPCA9685(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	boardpwm := NewBoardPCA9685(new(adaptorMock), 0x40, "TestNewBoardPCA9685")
+	// assert
+	require.NotNil(boardpwm)
+	assert.Equal("TestNewBoardPCA9685", boardpwm.name)
+	assert.Equal(16, len(boardpwm.GetPinNumbersOfType(boardpin.AnalogW)))
+}
+
+func TestWriteAnalog(t *testing.T) {
+	var writeAnalogTests = map[string]struct {
+		val    uint8
+		minVal uint8
+		maxVal uint8
+		want   string
+	}{
+		"InRange":    {val: 100, minVal: 0, maxVal: 255, want: "100"},
+		"BelowRange": {val: 5, minVal: 20, maxVal: 200, want: "20"},
+		"AboveRange": {val: 220, minVal: 20, maxVal: 200, want: "200"},
+	}
+	for name, wt := range writeAnalogTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			d := &pwmDriverMock{}
+			b := &Board{chips: map[string]*chip{"pwm": {driver: d}}}
+			bPin := &boardpin.Pin{ChipID: "pwm", ChipPinNr: 12, PinType: boardpin.AnalogW, MinVal: wt.minVal, MaxVal: wt.maxVal}
+			// act
+			err := b.writeAnalog(bPin, wt.val)
+			// assert
+			require.Nil(err)
+			assert.Equal("12", d.params["pin"])
+			assert.Equal(wt.want, d.params["val"])
+		})
+	}
+}
+
+func TestWriteAnalogWithoutDriverFails(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	b := &Board{}
+	// act
+	err := b.writeAnalog(&boardpin.Pin{}, 2)
+	// assert
+	assert.NotNil(err)
+}
+
+func TestWriteAnalogReturnsDriverError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	d := &pwmDriverMock{err: fmt.Errorf("PWM failed")}
+	b := &Board{chips: map[string]*chip{"pwm": {driver: d}}}
+	// act
+	err := b.writeAnalog(&boardpin.Pin{ChipID: "pwm", MaxVal: 255}, 2)
+	// assert
+	assert.Equal(d.err, err)
+}
+
+func TestSetServoMode(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardpwm := NewBoardPCA9685(new(adaptorMock), 0x40, "TestBoardPWM")
+	boardgpio := NewBoardType2io(new(adaptorMock), 0x04, "TestBoardGPIO")
+	// act
+	err := boardpwm.SetServoMode(3, true)
+	errUnknown := boardpwm.SetServoMode(16, true)
+	errGPIO := boardgpio.SetServoMode(3, true)
+	// assert
+	require.Nil(err)
+	assert.Contains(boardpwm.servoPins, uint8(3))
+	assert.NotNil(errUnknown)
+	require.NotNil(errGPIO)
+	assert.ErrorIs(errGPIO, ErrPinUsage)
+	require.Nil(boardpwm.SetServoMode(3, false))
+	assert.NotContains(boardpwm.servoPins, uint8(3))
+}
+
+func TestWriteServo(t *testing.T) {
+	var writeServoTests = map[string]struct {
+		val    uint8
+		minVal uint8
+		maxVal uint8
+		want   string
+	}{
+		"Min":        {val: 0, minVal: 0, maxVal: 255, want: "102"},
+		"Middle":     {val: 128, minVal: 0, maxVal: 255, want: "307"},
+		"Max":        {val: 255, minVal: 0, maxVal: 255, want: "512"},
+		"AboveRange": {val: 220, minVal: 20, maxVal: 200, want: "423"},
+	}
+	for name, wt := range writeServoTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			d := &pwmDriverMock{}
+			b := &Board{chips: map[string]*chip{"pwm": {driver: d}}}
+			bPin := &boardpin.Pin{ChipID: "pwm", ChipPinNr: 12, PinType: boardpin.AnalogW, MinVal: wt.minVal, MaxVal: wt.maxVal}
+			// act
+			err := b.writeServo(bPin, wt.val)
+			// assert
+			require.Nil(err)
+			assert.Equal("12", d.params["channel"])
+			assert.Equal("0", d.params["on"])
+			assert.Equal(wt.want, d.params["off"])
+		})
+	}
+}
+
+func TestWriteValueInServoModeSetsFrequencyOnce(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	d := &pwmDriverMock{}
+	b := &Board{
+		chips: map[string]*chip{"pwm": {driver: d}},
+		pins:  PinsMap{1: {ChipID: "pwm", ChipPinNr: 1, PinType: boardpin.AnalogW, MaxVal: 255}},
+	}
+	require.Nil(b.SetServoMode(1, true))
+	// act
+	err1 := b.WriteValue(1, 10)
+	err2 := b.WriteValue(1, 20)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	assert.Equal([]string{"SetPWMFreq", "SetPWM", "SetPWM"}, d.commands)
+}
+
+func TestWriteValueInServoModeSetsFrequencyAgainAfterExpire(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	d := &pwmDriverMock{}
+	b := &Board{
+		chips: map[string]*chip{"pwm": {driver: d}},
+		pins:  PinsMap{1: {ChipID: "pwm", ChipPinNr: 1, PinType: boardpin.AnalogW, MaxVal: 255}},
+	}
+	require.Nil(b.SetServoMode(1, true))
+	require.Nil(b.WriteValue(1, 10))
+	// act
+	b.ExpireOutputs()
+	err := b.WriteValue(1, 10)
+	// assert
+	require.Nil(err)
+	assert.Equal([]string{"SetPWMFreq", "SetPWM", "SetPWMFreq", "SetPWM"}, d.commands)
+}
+
+func TestWriteServoReturnsFrequencyError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	d := &pwmDriverMock{err: fmt.Errorf("frequency failed")}
+	b := &Board{chips: map[string]*chip{"pwm": {driver: d}}}
+	// act
+	err := b.writeServo(&boardpin.Pin{ChipID: "pwm", MaxVal: 255}, 2)
+	// assert
+	assert.Equal(d.err, err)
+	assert.Equal([]string{"SetPWMFreq"}, d.commands)
+	assert.False(b.chips["pwm"].servoFrequency)
+}
+
+func TestCommandError(t *testing.T) {
+	var commandErrorTests = map[string]struct {
+		result  interface{}
+		wantErr string
+	}{
+		"Nil":             {result: nil},
+		"NilError":        {result: error(nil)},
+		"Error":           {result: fmt.Errorf("direct error"), wantErr: "direct error"},
+		"MapWithoutError": {result: map[string]interface{}{"err": nil}},
+		"MapWithError":    {result: map[string]interface{}{"err": fmt.Errorf("map error")}, wantErr: "map error"},
+		"Unknown":         {result: 42, wantErr: "Unknown command result"},
+	}
+	for name, ct := range commandErrorTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			// act
+			err := commandError(ct.result)
+			// assert
+			if ct.wantErr == "" {
+				assert.Nil(err)
+			} else {
+				require.NotNil(err)
+				assert.Contains(err.Error(), ct.wantErr)
+			}
+		})
+	}
+}
+
+func (d *pwmDriverMock) Command(name string) (command func(map[string]interface{}) interface{}) {
+	switch name {
+	case "PwmWrite", "SetPWM", "SetPWMFreq":
+	default:
+		return nil
+	}
+	return func(params map[string]interface{}) interface{} {
+		d.commands = append(d.commands, name)
+		d.params = params
+		return d.err
+	}
+}
@@ -0,0 +1,83 @@
+package board
+
+// Implementation for circuit boards with one I2C chip PCF8574 or PCF8574A
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : some functions from gobot-i2c (PCA9501)
+//
+// 8574:
+// - 8 GPIO, quasi bidirectional, can only sink current, therefore outputs are low active
+// - address range 0x20..0x27 (PCF8574) and 0x38..0x3F (PCF8574A)
+// - there is no EEPROM on the chip
+// - gobot has no driver for this chip, but the PCA9501 driver is compatible for all GPIO commands
+//
+// Functions:
+// + read/write GPIO at board
+//
+
+import (
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+const chipIDPCF8574 = "PCF8574.GPIO"
+
+// this is the io configuration of PCF8574i
+var boardPinsPCF8574i = PinsMap{
+	0: {ChipID: chipIDPCF8574, ChipPinNr: 0, PinType: boardpin.NBinaryR},
+	1: {ChipID: chipIDPCF8574, ChipPinNr: 1, PinType: boardpin.NBinaryR},
+	2: {ChipID: chipIDPCF8574, ChipPinNr: 2, PinType: boardpin.NBinaryR},
+	3: {ChipID: chipIDPCF8574, ChipPinNr: 3, PinType: boardpin.NBinaryR},
+	4: {ChipID: chipIDPCF8574, ChipPinNr: 4, PinType: boardpin.NBinaryR},
+	5: {ChipID: chipIDPCF8574, ChipPinNr: 5, PinType: boardpin.NBinaryR},
+	6: {ChipID: chipIDPCF8574, ChipPinNr: 6, PinType: boardpin.NBinaryR},
+	7: {ChipID: chipIDPCF8574, ChipPinNr: 7, PinType: boardpin.NBinaryR},
+}
+
+// this is the io configuration of PCF8574o
+var boardPinsPCF8574o = PinsMap{
+	0: {ChipID: chipIDPCF8574, ChipPinNr: 0, PinType: boardpin.NBinaryW},
+	1: {ChipID: chipIDPCF8574, ChipPinNr: 1, PinType: boardpin.NBinaryW},
+	2: {ChipID: chipIDPCF8574, ChipPinNr: 2, PinType: boardpin.NBinaryW},
+	3: {ChipID: chipIDPCF8574, ChipPinNr: 3, PinType: boardpin.NBinaryW},
+	4: {ChipID: chipIDPCF8574, ChipPinNr: 4, PinType: boardpin.NBinaryW},
+	5: {ChipID: chipIDPCF8574, ChipPinNr: 5, PinType: boardpin.NBinaryW},
+	6: {ChipID: chipIDPCF8574, ChipPinNr: 6, PinType: boardpin.NBinaryW},
+	7: {ChipID: chipIDPCF8574, ChipPinNr: 7, PinType: boardpin.NBinaryW},
+}
+
+// this is the io configuration of PCF8574io
+var boardPinsPCF8574io = PinsMap{
+	0: {ChipID: chipIDPCF8574, ChipPinNr: 0, PinType: boardpin.NBinaryW},
+	1: {ChipID: chipIDPCF8574, ChipPinNr: 1, PinType: boardpin.NBinaryW},
+	2: {ChipID: chipIDPCF8574, ChipPinNr: 2, PinType: boardpin.NBinaryW},
+	3: {ChipID: chipIDPCF8574, ChipPinNr: 3, PinType: boardpin.NBinaryW},
+	4: {ChipID: chipIDPCF8574, ChipPinNr: 4, PinType: boardpin.NBinaryR},
+	5: {ChipID: chipIDPCF8574, ChipPinNr: 5, PinType: boardpin.NBinaryR},
+	6: {ChipID: chipIDPCF8574, ChipPinNr: 6, PinType: boardpin.NBinaryR},
+	7: {ChipID: chipIDPCF8574, ChipPinNr: 7, PinType: boardpin.NBinaryR},
+}
+
+// NewBoardPCF8574i creates a new board with a PCF8574 and 8 inputs (negotiated read).
+func NewBoardPCF8574i(adaptor i2c.Connector, address uint8, name string) *Board {
+	return NewBoard(name, newPCF8574Chips(adaptor, address, boardPinsPCF8574i), boardPinsPCF8574i, "PCF8574i")
+}
+
+// NewBoardPCF8574o creates a new board with a PCF8574 and 8 outputs (negotiated write).
+func NewBoardPCF8574o(adaptor i2c.Connector, address uint8, name string) *Board {
+	return NewBoard(name, newPCF8574Chips(adaptor, address, boardPinsPCF8574o), boardPinsPCF8574o, "PCF8574o")
+}
+
+// NewBoardPCF8574io creates a new board with a PCF8574, 4 outputs (negotiated write) and 4 inputs (negotiated read).
+// Pin 0..3 are output and 4..7 are input pins.
+func NewBoardPCF8574io(adaptor i2c.Connector, address uint8, name string) *Board {
+	return NewBoard(name, newPCF8574Chips(adaptor, address, boardPinsPCF8574io), boardPinsPCF8574io, "PCF8574io")
+}
+
+func newPCF8574Chips(adaptor i2c.Connector, address uint8, pins PinsMap) map[string]*chip {
+	// the PCF8574 behaves like the GPIO part of the PCA9501
+	return map[string]*chip{chipIDPCF8574: newPCA9501Chip(adaptor, address, pins.inputMask(chipIDPCF8574))}
+}
@@ -0,0 +1,51 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+func TestNewBoardPCF8574i(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	boardpcf := NewBoardPCF8574i(new(adaptorMock), 0x20, "TestNewBoardPCF8574i")
+	// assert
+	require.NotNil(boardpcf)
+	assert.Equal("TestNewBoardPCF8574i", boardpcf.name)
+	assert.Equal(8, len(boardpcf.GetPinNumbers()))
+	assert.Equal(boardpin.NBinaryR, boardpcf.pins[7].PinType)
+}
+
+func TestNewBoardPCF8574o(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	boardpcf := NewBoardPCF8574o(new(adaptorMock), 0x27, "TestNewBoardPCF8574o")
+	// assert
+	require.NotNil(boardpcf)
+	assert.Equal("TestNewBoardPCF8574o", boardpcf.name)
+	assert.Equal(8, len(boardpcf.GetPinNumbers()))
+	assert.Equal(boardpin.NBinaryW, boardpcf.pins[0].PinType)
+}
+
+func TestNewBoardPCF8574io(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	boardpcf := NewBoardPCF8574io(new(adaptorMock), 0x38, "TestNewBoardPCF8574io")
+	// assert
+	require.NotNil(boardpcf)
+	assert.Equal("TestNewBoardPCF8574io", boardpcf.name)
+	assert.Equal(8, len(boardpcf.GetPinNumbers()))
+	assert.Equal(boardpin.NBinaryW, boardpcf.pins[3].PinType)
+	assert.Equal(boardpin.NBinaryR, boardpcf.pins[4].PinType)
+	assert.Equal(uint8(0x38), boardpcf.chips[chipIDPCF8574].address)
+}
@@ -104,13 +104,26 @@ func TestGetBoardPinNotThereGetsError(t *testing.T) {
 	pin, err := testBoard.getBoardPin(3)
 	// assert
 	assert.NotNil(err)
+	assert.ErrorIs(err, ErrPinUsage)
 	assert.Nil(pin)
 }
 
+func TestWriteValueToReadOnlyPinIsPinUsageError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	b := &Board{}
+	b.chips = map[string]*chip{"chipNR": {driver: &deviceMock{name: "dev1"}}}
+	b.pins = PinsMap{3: {ChipID: "chipNR", PinType: boardpin.BinaryR}}
+	// act
+	err := b.WriteValue(3, 1)
+	// assert
+	assert.ErrorIs(err, ErrPinUsage)
+}
+
 func TestWriteValue(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
-	// note: analog is not implemented yet, therefore fails
+	// note: analog read is not implemented yet, therefore fails
 	var wTests = []rwTest{
 		{pType: boardpin.Binary, fails: false, expVal: uint8(1)},
 		{pType: boardpin.BinaryR, fails: true, expVal: uint8(1)},
@@ -121,9 +134,9 @@ func TestWriteValue(t *testing.T) {
 		{pType: boardpin.Memory, fails: false, expVal: uint8(1)},
 		{pType: boardpin.MemoryR, fails: true, expVal: uint8(1)},
 		{pType: boardpin.MemoryW, fails: false, expVal: uint8(0)},
-		{pType: boardpin.Analog, fails: true, expVal: uint8(0)},
+		{pType: boardpin.Analog, fails: false, expVal: uint8(0)},
 		{pType: boardpin.AnalogR, fails: true, expVal: uint8(0)},
-		{pType: boardpin.AnalogW, fails: true, expVal: uint8(0)},
+		{pType: boardpin.AnalogW, fails: false, expVal: uint8(0)},
 	}
 	for _, wt := range wTests {
 		name := "for " + boardpin.PinTypeMsgMap[wt.pType]
@@ -146,7 +159,7 @@ func TestWriteValue(t *testing.T) {
 func TestReadValue(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
-	// note: analog is not implemented yet, therefore fails
+	// note: analog read is not implemented yet, therefore fails
 	var rTests = []rwTest{
 		{pType: boardpin.Binary, fails: false, expVal: uint8(1)},
 		{pType: boardpin.BinaryR, fails: false, expVal: uint8(1)},
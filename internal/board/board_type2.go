@@ -12,26 +12,24 @@ package board
 // 9501:
 // - 8 GPIO, 0..3 amplified and negotiated with IRLZ34N, 4..7 for max. 20mA (see docs/images)
 // - Dummy address for EEPROM write is set to 0x00
+// - EEPROM is a separate chip at address + 0x40, the board pins 8..15 are mapped to 0x01..0x08 by default,
+//   another range can be configured, the area 0xC0..0xFF is reserved for the identity of the board
+// - the pins 8, 9, 11..15 keep the addresses 0x01..0x07 of former versions, only the pin 10 (formerly a duplicate of
+//   pin 9 at 0x02) is moved to 0x08, so stored values are still valid
 //
 // Functions:
 // + read/write EEPROM at board
 // + read/write GPIO at board
 //
-// TODO:
-// - provide EEPROM as separate "chip" of this board
-// - config with "from - to" range, especially for memory
-// - ensure no overlap of EEPROM for general use with "configmode"
-//
 
 import (
-	"time"
-
 	"gobot.io/x/gobot/drivers/i2c"
 
 	"github.com/gen2thomas/gobrail/internal/boardpin"
 )
 
-const chipID = "PCA9501.GPIO.Mem"
+const chipID = "PCA9501.GPIO"
+const chipIDEEPROM = "PCA9501" + eepromChipIDSuffix
 
 //this is the io configuration of Type2i
 var boardPinsType2i = PinsMap{
@@ -43,14 +41,14 @@ var boardPinsType2i = PinsMap{
 	5:  {ChipID: chipID, ChipPinNr: 5, PinType: boardpin.NBinaryR},
 	6:  {ChipID: chipID, ChipPinNr: 6, PinType: boardpin.NBinaryR},
 	7:  {ChipID: chipID, ChipPinNr: 7, PinType: boardpin.NBinaryR},
-	8:  {ChipID: chipID, ChipPinNr: 0x01, PinType: boardpin.Memory},
-	9:  {ChipID: chipID, ChipPinNr: 0x02, PinType: boardpin.Memory},
-	10: {ChipID: chipID, ChipPinNr: 0x02, PinType: boardpin.Memory},
-	11: {ChipID: chipID, ChipPinNr: 0x03, PinType: boardpin.Memory},
-	12: {ChipID: chipID, ChipPinNr: 0x04, PinType: boardpin.Memory},
-	13: {ChipID: chipID, ChipPinNr: 0x05, PinType: boardpin.Memory},
-	14: {ChipID: chipID, ChipPinNr: 0x06, PinType: boardpin.Memory},
-	15: {ChipID: chipID, ChipPinNr: 0x07, PinType: boardpin.Memory},
+	8:  {ChipID: chipIDEEPROM, ChipPinNr: 0x01, PinType: boardpin.Memory},
+	9:  {ChipID: chipIDEEPROM, ChipPinNr: 0x02, PinType: boardpin.Memory},
+	10: {ChipID: chipIDEEPROM, ChipPinNr: 0x08, PinType: boardpin.Memory},
+	11: {ChipID: chipIDEEPROM, ChipPinNr: 0x03, PinType: boardpin.Memory},
+	12: {ChipID: chipIDEEPROM, ChipPinNr: 0x04, PinType: boardpin.Memory},
+	13: {ChipID: chipIDEEPROM, ChipPinNr: 0x05, PinType: boardpin.Memory},
+	14: {ChipID: chipIDEEPROM, ChipPinNr: 0x06, PinType: boardpin.Memory},
+	15: {ChipID: chipIDEEPROM, ChipPinNr: 0x07, PinType: boardpin.Memory},
 }
 
 //this is the io configuration of Type2o
@@ -63,14 +61,14 @@ var boardPinsType2o = PinsMap{
 	5:  {ChipID: chipID, ChipPinNr: 5, PinType: boardpin.BinaryW},
 	6:  {ChipID: chipID, ChipPinNr: 6, PinType: boardpin.BinaryW},
 	7:  {ChipID: chipID, ChipPinNr: 7, PinType: boardpin.BinaryW},
-	8:  {ChipID: chipID, ChipPinNr: 0x01, PinType: boardpin.Memory},
-	9:  {ChipID: chipID, ChipPinNr: 0x02, PinType: boardpin.Memory},
-	10: {ChipID: chipID, ChipPinNr: 0x02, PinType: boardpin.Memory},
-	11: {ChipID: chipID, ChipPinNr: 0x03, PinType: boardpin.Memory},
-	12: {ChipID: chipID, ChipPinNr: 0x04, PinType: boardpin.Memory},
-	13: {ChipID: chipID, ChipPinNr: 0x05, PinType: boardpin.Memory},
-	14: {ChipID: chipID, ChipPinNr: 0x06, PinType: boardpin.Memory},
-	15: {ChipID: chipID, ChipPinNr: 0x07, PinType: boardpin.Memory},
+	8:  {ChipID: chipIDEEPROM, ChipPinNr: 0x01, PinType: boardpin.Memory},
+	9:  {ChipID: chipIDEEPROM, ChipPinNr: 0x02, PinType: boardpin.Memory},
+	10: {ChipID: chipIDEEPROM, ChipPinNr: 0x08, PinType: boardpin.Memory},
+	11: {ChipID: chipIDEEPROM, ChipPinNr: 0x03, PinType: boardpin.Memory},
+	12: {ChipID: chipIDEEPROM, ChipPinNr: 0x04, PinType: boardpin.Memory},
+	13: {ChipID: chipIDEEPROM, ChipPinNr: 0x05, PinType: boardpin.Memory},
+	14: {ChipID: chipIDEEPROM, ChipPinNr: 0x06, PinType: boardpin.Memory},
+	15: {ChipID: chipIDEEPROM, ChipPinNr: 0x07, PinType: boardpin.Memory},
 }
 
 //this is the io configuration of Type2io
@@ -83,32 +81,26 @@ var boardPinsType2io = PinsMap{
 	5:  {ChipID: chipID, ChipPinNr: 5, PinType: boardpin.NBinaryR},
 	6:  {ChipID: chipID, ChipPinNr: 6, PinType: boardpin.NBinaryR},
 	7:  {ChipID: chipID, ChipPinNr: 7, PinType: boardpin.NBinaryR},
-	8:  {ChipID: chipID, ChipPinNr: 0x01, PinType: boardpin.Memory},
-	9:  {ChipID: chipID, ChipPinNr: 0x02, PinType: boardpin.Memory},
-	10: {ChipID: chipID, ChipPinNr: 0x02, PinType: boardpin.Memory},
-	11: {ChipID: chipID, ChipPinNr: 0x03, PinType: boardpin.Memory},
-	12: {ChipID: chipID, ChipPinNr: 0x04, PinType: boardpin.Memory},
-	13: {ChipID: chipID, ChipPinNr: 0x05, PinType: boardpin.Memory},
-	14: {ChipID: chipID, ChipPinNr: 0x06, PinType: boardpin.Memory},
-	15: {ChipID: chipID, ChipPinNr: 0x07, PinType: boardpin.Memory},
+	8:  {ChipID: chipIDEEPROM, ChipPinNr: 0x01, PinType: boardpin.Memory},
+	9:  {ChipID: chipIDEEPROM, ChipPinNr: 0x02, PinType: boardpin.Memory},
+	10: {ChipID: chipIDEEPROM, ChipPinNr: 0x08, PinType: boardpin.Memory},
+	11: {ChipID: chipIDEEPROM, ChipPinNr: 0x03, PinType: boardpin.Memory},
+	12: {ChipID: chipIDEEPROM, ChipPinNr: 0x04, PinType: boardpin.Memory},
+	13: {ChipID: chipIDEEPROM, ChipPinNr: 0x05, PinType: boardpin.Memory},
+	14: {ChipID: chipIDEEPROM, ChipPinNr: 0x06, PinType: boardpin.Memory},
+	15: {ChipID: chipIDEEPROM, ChipPinNr: 0x07, PinType: boardpin.Memory},
 }
 
 // NewBoardType2i creates a new board of type 2 with 8 inputs (negotiated read).
 func NewBoardType2i(adaptor i2c.Connector, address uint8, name string) *Board {
-	chips := map[string]*chip{chipID: {
-		address: address,
-		driver:  i2c.NewPCA9501Driver(adaptor, i2c.WithAddress(int(address))),
-	}}
+	chips := newPCA9501Chips(adaptor, address, boardPinsType2i.inputMask(chipID))
 
 	return NewBoard(name, chips, boardPinsType2i, "Type2i")
 }
 
 // NewBoardType2o creates a new board of type 2 with 8 outputs.
 func NewBoardType2o(adaptor i2c.Connector, address uint8, name string) *Board {
-	chips := map[string]*chip{chipID: {
-		address: address,
-		driver:  i2c.NewPCA9501Driver(adaptor, i2c.WithAddress(int(address))),
-	}}
+	chips := newPCA9501Chips(adaptor, address, boardPinsType2o.inputMask(chipID))
 
 	return NewBoard(name, chips, boardPinsType2o, "Type2o")
 }
@@ -116,15 +108,36 @@ func NewBoardType2o(adaptor i2c.Connector, address uint8, name string) *Board {
 // NewBoardType2io creates a new board of type 2 with 4 inputs (negotiated read) and 4 outputs.
 // Pin 0..3 are output and 4..7 are input pins.
 func NewBoardType2io(adaptor i2c.Connector, address uint8, name string) *Board {
-	chips := map[string]*chip{chipID: {
-		address: address,
-		driver:  i2c.NewPCA9501Driver(adaptor, i2c.WithAddress(int(address))),
-	}}
+	chips := newPCA9501Chips(adaptor, address, boardPinsType2io.inputMask(chipID))
 
 	return NewBoard(name, chips, boardPinsType2io, "Type2io")
 }
 
+// newPCA9501Chips creates the GPIO chip and the EEPROM chip, both using the same PCA9501 driver
+func newPCA9501Chips(adaptor i2c.Connector, address uint8, inputMask uint16) map[string]*chip {
+	gpio := newPCA9501Chip(adaptor, address, inputMask)
+	return map[string]*chip{chipID: gpio, chipIDEEPROM: newEEPROMChip(gpio.driver, address|0x40)}
+}
+
+// newPCA9501Chip creates a chip with a PCA9501 driver, the GPIO is read and written as a quasi bidirectional port
+func newPCA9501Chip(adaptor i2c.Connector, address uint8, inputMask uint16) *chip {
+	chipCon := newChipConnection(adaptor, address)
+	return &chip{
+		address: address,
+		driver:  i2c.NewPCA9501Driver(adaptor, i2c.WithAddress(int(address))),
+		port:    newQuasiBidirectionalPort(chipCon, uint8(inputMask)),
+		outputs: newQuasiBidirectionalOutputPort(chipCon, uint8(inputMask)),
+	}
+}
+
 func (b *Board) writeGPIO(bPin *boardpin.Pin, val uint8) (err error) {
+	if outputs := b.getOutputPort(bPin); outputs != nil {
+		err = outputs.pin(bPin.ChipPinNr, val)
+		if port := b.getInputPort(bPin); port != nil {
+			port.expire()
+		}
+		return
+	}
 	var driver DriverOperations
 	if driver, err = b.getDriver(bPin); err != nil {
 		return
@@ -134,6 +147,9 @@ func (b *Board) writeGPIO(bPin *boardpin.Pin, val uint8) (err error) {
 		"val": val,
 	}
 	result := driver.Command("WriteGPIO")(params).(map[string]interface{})["err"]
+	if port := b.getInputPort(bPin); port != nil {
+		port.expire()
+	}
 	if result != nil {
 		return result.(error)
 	}
@@ -141,6 +157,9 @@ func (b *Board) writeGPIO(bPin *boardpin.Pin, val uint8) (err error) {
 }
 
 func (b *Board) readGPIO(bPin *boardpin.Pin) (val uint8, err error) {
+	if port := b.getInputPort(bPin); port != nil {
+		return port.pin(bPin.ChipPinNr)
+	}
 	var driver DriverOperations
 	if driver, err = b.getDriver(bPin); err != nil {
 		return
@@ -153,35 +172,3 @@ func (b *Board) readGPIO(bPin *boardpin.Pin) (val uint8, err error) {
 	}
 	return result["val"].(uint8), nil
 }
-
-func (b *Board) writeEEPROM(bPin *boardpin.Pin, val uint8) (err error) {
-	var driver DriverOperations
-	if driver, err = b.getDriver(bPin); err != nil {
-		return
-	}
-	var params = map[string]interface{}{
-		"address": bPin.ChipPinNr,
-		"val":     val,
-	}
-	result := driver.Command("WriteEEPROM")(params).(map[string]interface{})["err"]
-	time.Sleep(4 * time.Millisecond)
-	if result != nil {
-		return result.(error)
-	}
-	return
-}
-
-func (b *Board) readEEPROM(bPin *boardpin.Pin) (val uint8, err error) {
-	var driver DriverOperations
-	if driver, err = b.getDriver(bPin); err != nil {
-		return
-	}
-	params := make(map[string]interface{})
-	params["address"] = bPin.ChipPinNr
-	result := driver.Command("ReadEEPROM")(params).(map[string]interface{})
-	time.Sleep(4 * time.Millisecond)
-	if result["err"] != nil {
-		return 0, result["err"].(error)
-	}
-	return result["val"].(uint8), nil
-}
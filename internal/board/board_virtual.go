@@ -0,0 +1,149 @@
+package board
+
+// Implementation for a virtual board "Virtual8io" without any hardware
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : nothing, the chip is simulated in memory
+//
+// The virtual chip behaves like a PCA9501 (8 GPIO, 256 byte EEPROM) and accepts the same commands.
+// An input can be simulated by calling the command "WriteGPIO" of the driver, e.g. from a test.
+//
+// Functions:
+// + read/write GPIO in memory
+// + read/write EEPROM in memory
+//
+
+import (
+	"sync"
+
+	"gobot.io/x/gobot"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+const chipIDVirtual = "Virtual.GPIO.Mem"
+
+// this is the io configuration of Virtual8io
+var boardPinsVirtual8io = PinsMap{
+	0:  {ChipID: chipIDVirtual, ChipPinNr: 0, PinType: boardpin.Binary},
+	1:  {ChipID: chipIDVirtual, ChipPinNr: 1, PinType: boardpin.Binary},
+	2:  {ChipID: chipIDVirtual, ChipPinNr: 2, PinType: boardpin.Binary},
+	3:  {ChipID: chipIDVirtual, ChipPinNr: 3, PinType: boardpin.Binary},
+	4:  {ChipID: chipIDVirtual, ChipPinNr: 4, PinType: boardpin.Binary},
+	5:  {ChipID: chipIDVirtual, ChipPinNr: 5, PinType: boardpin.Binary},
+	6:  {ChipID: chipIDVirtual, ChipPinNr: 6, PinType: boardpin.Binary},
+	7:  {ChipID: chipIDVirtual, ChipPinNr: 7, PinType: boardpin.Binary},
+	8:  {ChipID: chipIDVirtual, ChipPinNr: 0x00, PinType: boardpin.Memory},
+	9:  {ChipID: chipIDVirtual, ChipPinNr: 0x01, PinType: boardpin.Memory},
+	10: {ChipID: chipIDVirtual, ChipPinNr: 0x02, PinType: boardpin.Memory},
+	11: {ChipID: chipIDVirtual, ChipPinNr: 0x03, PinType: boardpin.Memory},
+	12: {ChipID: chipIDVirtual, ChipPinNr: 0x04, PinType: boardpin.Memory},
+	13: {ChipID: chipIDVirtual, ChipPinNr: 0x05, PinType: boardpin.Memory},
+	14: {ChipID: chipIDVirtual, ChipPinNr: 0x06, PinType: boardpin.Memory},
+	15: {ChipID: chipIDVirtual, ChipPinNr: 0x07, PinType: boardpin.Memory},
+}
+
+type virtualDriver struct {
+	name   string
+	gpio   uint8
+	eeprom [256]uint8
+	mutex  *sync.Mutex
+	gobot.Commander
+}
+
+// NewBoardVirtual8io creates a new virtual board with 8 inputs/outputs and memory, no adaptor is needed.
+func NewBoardVirtual8io(name string) *Board {
+	driver := newVirtualDriver()
+	chips := map[string]*chip{chipIDVirtual: {
+		driver: driver,
+		port:   &inputPort{read: driver.readPort},
+	}}
+
+	return NewBoard(name, chips, boardPinsVirtual8io, "Virtual8io")
+}
+
+func newVirtualDriver() *virtualDriver {
+	d := &virtualDriver{
+		name:      gobot.DefaultName("Virtual8io"),
+		mutex:     &sync.Mutex{},
+		Commander: gobot.NewCommander(),
+	}
+
+	d.AddCommand("WriteGPIO", func(params map[string]interface{}) interface{} {
+		d.writeGPIO(params["pin"].(uint8), params["val"].(uint8))
+		return map[string]interface{}{"err": nil}
+	})
+
+	d.AddCommand("ReadGPIO", func(params map[string]interface{}) interface{} {
+		val := d.readGPIO(params["pin"].(uint8))
+		return map[string]interface{}{"val": val, "err": nil}
+	})
+
+	d.AddCommand("WriteEEPROM", func(params map[string]interface{}) interface{} {
+		d.writeEEPROM(params["address"].(uint8), params["val"].(uint8))
+		return map[string]interface{}{"err": nil}
+	})
+
+	d.AddCommand("ReadEEPROM", func(params map[string]interface{}) interface{} {
+		val := d.readEEPROM(params["address"].(uint8))
+		return map[string]interface{}{"val": val, "err": nil}
+	})
+
+	return d
+}
+
+// Name returns the name of the virtual driver
+func (d *virtualDriver) Name() string { return d.name }
+
+// SetName sets the name of the virtual driver
+func (d *virtualDriver) SetName(name string) { d.name = name }
+
+// Start does nothing, because there is no hardware
+func (d *virtualDriver) Start() (err error) { return }
+
+// Halt does nothing, because there is no hardware
+func (d *virtualDriver) Halt() (err error) { return }
+
+// Connection returns nil, because there is no adaptor
+func (d *virtualDriver) Connection() gobot.Connection { return nil }
+
+func (d *virtualDriver) writeGPIO(pin uint8, val uint8) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if val == 0 {
+		d.gpio &^= 1 << pin
+	} else {
+		d.gpio |= 1 << pin
+	}
+}
+
+func (d *virtualDriver) readGPIO(pin uint8) uint8 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return (d.gpio >> pin) & 0x01
+}
+
+func (d *virtualDriver) readPort() (value uint16, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return uint16(d.gpio), nil
+}
+
+func (d *virtualDriver) writeEEPROM(address uint8, val uint8) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.eeprom[address] = val
+}
+
+func (d *virtualDriver) readEEPROM(address uint8) uint8 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.eeprom[address]
+}
@@ -0,0 +1,93 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+func TestNewBoardVirtual8io(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	boardv := NewBoardVirtual8io("TestNewBoardVirtual8io")
+	// assert
+	require.NotNil(boardv)
+	assert.Equal("TestNewBoardVirtual8io", boardv.name)
+	assert.Equal(16, len(boardv.GetPinNumbers()))
+	require.Equal(1, len(boardv.GobotDevices()))
+	assert.Nil(boardv.GobotDevices()[0].Connection())
+}
+
+func TestVirtual8ioWriteReadGPIO(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardv := NewBoardVirtual8io("TestVirtual8ioWriteReadGPIO")
+	// act
+	err1 := boardv.WriteValue(3, 1)
+	val3, err2 := boardv.ReadValue(3)
+	val4, err3 := boardv.ReadValue(4)
+	err4 := boardv.WriteValue(3, 0)
+	val3Off, err5 := boardv.ReadValue(3)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	require.Nil(err4)
+	require.Nil(err5)
+	assert.Equal(uint8(1), val3)
+	assert.Equal(uint8(0), val4)
+	assert.Equal(uint8(0), val3Off)
+}
+
+func TestVirtual8ioSimulateInputByCommand(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardv := NewBoardVirtual8io("TestVirtual8ioSimulateInputByCommand")
+	driver := boardv.GobotDevices()[0].(DriverOperations)
+	// act
+	driver.Command("WriteGPIO")(map[string]interface{}{"pin": uint8(6), "val": uint8(1)})
+	val, err := boardv.ReadValue(6)
+	// assert
+	require.Nil(err)
+	assert.Equal(uint8(1), val)
+}
+
+func TestVirtual8ioWriteReadEEPROM(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardv := NewBoardVirtual8io("TestVirtual8ioWriteReadEEPROM")
+	// act
+	err1 := boardv.WriteValue(9, 0x5A)
+	val9, err2 := boardv.ReadValue(9)
+	val10, err3 := boardv.ReadValue(10)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	assert.Equal(uint8(0x5A), val9)
+	assert.Equal(uint8(0), val10)
+}
+
+func TestVirtual8ioNegatedPinType(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardv := NewBoardVirtual8io("TestVirtual8ioNegatedPinType")
+	pins := PinsMap{0: {ChipID: chipIDVirtual, ChipPinNr: 0, PinType: boardpin.NBinaryR}}
+	boardn := NewBoard("TestVirtual8ioNegated", boardv.chips, pins, "Virtual8io")
+	// act
+	val, err := boardn.ReadValue(0)
+	errWrite := boardn.WriteValue(0, 1)
+	// assert
+	require.Nil(err)
+	assert.Equal(uint8(1), val)
+	assert.NotNil(errWrite)
+}
@@ -0,0 +1,38 @@
+package board
+
+// The chip connection is a direct i2c connection to a chip, which is used additionally to the gobot driver for
+// accessing the whole port of the chip at once
+
+import (
+	"fmt"
+
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+type chipConnection struct {
+	adaptor    i2c.Connector
+	address    uint8
+	connection i2c.Connection
+}
+
+func newChipConnection(adaptor i2c.Connector, address uint8) *chipConnection {
+	return &chipConnection{adaptor: adaptor, address: address}
+}
+
+// get creates the connection on first call, this must be done after the adaptor is connected
+func (c *chipConnection) get() (connection i2c.Connection, err error) {
+	if c.connection != nil {
+		return c.connection, nil
+	}
+	if c.adaptor == nil {
+		return nil, fmt.Errorf("No adaptor for chip at address 0x%02X", c.address)
+	}
+	if connection, err = c.adaptor.GetConnection(int(c.address), c.adaptor.GetDefaultBus()); err != nil {
+		return
+	}
+	if connection == nil {
+		return nil, fmt.Errorf("No connection for chip at address 0x%02X", c.address)
+	}
+	c.connection = connection
+	return
+}
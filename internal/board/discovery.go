@@ -0,0 +1,55 @@
+package board
+
+// Discovery of boards at the i2c bus (configmode)
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : some functions from gobot-i2c (connection)
+//
+// A PCA9501 is identified by its address pair, the GPIO part at 0x00..0x3F and the EEPROM part at the same address
+// with bit 6 set (0x40..0x7F). Only if both parts responds to a read, the address is reported.
+// The probing is done by reading only, so no output will be changed.
+//
+// Functions:
+// + search for main address of PCA9501 boards
+// + exclude already used addresses
+//
+
+import (
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+const pca9501MaxAddress = 0x3F
+const pca9501MemFlag = 0x40
+
+// SearchPCA9501 probes the i2c bus for PCA9501 chips and returns the GPIO address of each found chip. All given
+// addresses are excluded from search, also when used as EEPROM part.
+func SearchPCA9501(adaptor i2c.Connector, excludedAddresses []uint8) (found []uint8) {
+	excluded := make(map[uint8]struct{})
+	for _, address := range excludedAddresses {
+		excluded[address] = struct{}{}
+	}
+	for address := uint8(0); address <= pca9501MaxAddress; address++ {
+		if _, ok := excluded[address]; ok {
+			continue
+		}
+		if _, ok := excluded[address|pca9501MemFlag]; ok {
+			continue
+		}
+		if !respondsToRead(adaptor, address) || !respondsToRead(adaptor, address|pca9501MemFlag) {
+			continue
+		}
+		found = append(found, address)
+	}
+	return
+}
+
+func respondsToRead(adaptor i2c.Connector, address uint8) bool {
+	connection, err := newChipConnection(adaptor, address).get()
+	if err != nil {
+		return false
+	}
+	_, err = connection.ReadByte()
+	return err == nil
+}
@@ -0,0 +1,60 @@
+package board
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+type busConnectorMock struct {
+	present map[int]bool
+}
+
+type probeConnectionMock struct {
+	portConnectionMock
+	present bool
+}
+
+func TestSearchPCA9501(t *testing.T) {
+	var searchTests = map[string]struct {
+		present  []int
+		excluded []uint8
+		want     []uint8
+	}{
+		"NothingPresent":      {},
+		"GPIOAndEEPROM":       {present: []int{0x04, 0x44, 0x3F, 0x7F}, want: []uint8{0x04, 0x3F}},
+		"OnlyGPIO":            {present: []int{0x04, 0x05, 0x45}, want: []uint8{0x05}},
+		"OnlyEEPROM":          {present: []int{0x44}},
+		"OtherChipsIgnored":   {present: []int{0x20, 0x40, 0x70}},
+		"ExcludedGPIO":        {present: []int{0x04, 0x44, 0x05, 0x45}, excluded: []uint8{0x05}, want: []uint8{0x04}},
+		"ExcludedEEPROMRange": {present: []int{0x04, 0x44, 0x05, 0x45}, excluded: []uint8{0x44}, want: []uint8{0x05}},
+	}
+	for name, st := range searchTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			connector := &busConnectorMock{present: make(map[int]bool)}
+			for _, address := range st.present {
+				connector.present[address] = true
+			}
+			// act
+			found := SearchPCA9501(connector, st.excluded)
+			// assert
+			assert.Equal(st.want, found)
+		})
+	}
+}
+
+func (a *busConnectorMock) GetConnection(address int, bus int) (device i2c.Connection, err error) {
+	return &probeConnectionMock{present: a.present[address]}, nil
+}
+func (a *busConnectorMock) GetDefaultBus() int { return 0 }
+
+func (c *probeConnectionMock) ReadByte() (val byte, err error) {
+	if !c.present {
+		return 0, fmt.Errorf("No ACK")
+	}
+	return 0xFF, nil
+}
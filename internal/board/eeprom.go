@@ -0,0 +1,159 @@
+package board
+
+// The EEPROM of a chip is provided as a separate chip of the board
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: board, identity
+// Call       : the commands "ReadEEPROM" and "WriteEEPROM" of the driver
+//
+// The EEPROM is divided in a range for general use (memory pins of the board) and a reserved area for metadata of
+// the board (e.g. the identity), which is never mapped to a board pin. All known values are kept in a shadow, so a
+// value is only read once and a write of an unchanged value is skipped to save write cycles of the EEPROM. After
+// a write the EEPROM is busy for the write cycle time, the next access waits until the write cycle is finished.
+//
+// Functions:
+// + read/write EEPROM with shadow
+// + wait for finished write cycle before next access
+// + map a range of the EEPROM to the memory pins of the board
+//
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+const eepromReservedStart = 0xC0
+const eepromChipIDSuffix = ".EEPROM"
+const eepromWriteCycle = 4 * time.Millisecond
+
+// memoryPinTypes are all types of pins, which are mapped to an EEPROM
+var memoryPinTypes = []boardpin.PinType{boardpin.Memory, boardpin.MemoryR, boardpin.MemoryW}
+
+// eeprom is the shadow of all known values and the end of the last write cycle
+type eeprom struct {
+	values    map[uint8]uint8
+	writeDone time.Time
+}
+
+func newEEPROM() *eeprom {
+	return &eeprom{values: make(map[uint8]uint8)}
+}
+
+// newEEPROMChip creates a chip for the EEPROM, the driver is shared with the GPIO chip and the address is
+// the bus address of the EEPROM part
+func newEEPROMChip(driver DriverOperations, address uint8) *chip {
+	return &chip{address: address, driver: driver, memory: newEEPROM()}
+}
+
+// SetMemoryRange maps the EEPROM addresses "from..to" to the memory pins of the board, the first memory pin keeps
+// its board pin number and all other memory pins follow in sequence. The reserved area of the EEPROM can not be used.
+func (b *Board) SetMemoryRange(from uint8, to uint8) (err error) {
+	if from > to || to >= eepromReservedStart {
+		return fmt.Errorf("The memory range 0x%02X..0x%02X is not valid, allowed is 0x00..0x%02X", from, to,
+			eepromReservedStart-1)
+	}
+	var memChipID string
+	if memChipID, err = b.getMemoryChipID(); err != nil {
+		return
+	}
+	firstPinNr := -1
+	for pinNr, bPin := range b.pins {
+		if bPin.ChipID == memChipID && bPin.PinTypeIsOneOf(memoryPinTypes) {
+			if firstPinNr < 0 || int(pinNr) < firstPinNr {
+				firstPinNr = int(pinNr)
+			}
+		}
+	}
+	if firstPinNr+int(to-from) > 0xFF {
+		return fmt.Errorf("The memory range 0x%02X..0x%02X exceeds the board pins, starting at %d", from, to,
+			firstPinNr)
+	}
+	pins := make(PinsMap)
+	for pinNr, bPin := range b.pins {
+		if bPin.ChipID == memChipID && bPin.PinTypeIsOneOf(memoryPinTypes) {
+			continue
+		}
+		if int(pinNr) >= firstPinNr && int(pinNr) <= firstPinNr+int(to-from) {
+			return fmt.Errorf("The memory range 0x%02X..0x%02X overlaps with board pin %d", from, to, pinNr)
+		}
+		pins[pinNr] = bPin
+	}
+	for address := int(from); address <= int(to); address++ {
+		pinNr := uint8(firstPinNr + address - int(from))
+		pins[pinNr] = &boardpin.Pin{ChipID: memChipID, ChipPinNr: uint8(address), PinType: boardpin.Memory}
+	}
+	b.pins = pins
+	return
+}
+
+func (b *Board) writeEEPROM(bPin *boardpin.Pin, val uint8) (err error) {
+	var driver DriverOperations
+	if driver, err = b.getDriver(bPin); err != nil {
+		return
+	}
+	mem := b.getMemory(bPin)
+	if mem != nil {
+		if known, ok := mem.values[bPin.ChipPinNr]; ok && known == val {
+			return
+		}
+		mem.waitForWriteCycle()
+	}
+	var params = map[string]interface{}{
+		"address": bPin.ChipPinNr,
+		"val":     val,
+	}
+	result := driver.Command("WriteEEPROM")(params).(map[string]interface{})["err"]
+	if mem != nil {
+		mem.writeDone = time.Now().Add(eepromWriteCycle)
+		delete(mem.values, bPin.ChipPinNr)
+	}
+	if result != nil {
+		return result.(error)
+	}
+	if mem != nil {
+		mem.values[bPin.ChipPinNr] = val
+	}
+	return
+}
+
+func (b *Board) readEEPROM(bPin *boardpin.Pin) (val uint8, err error) {
+	var driver DriverOperations
+	if driver, err = b.getDriver(bPin); err != nil {
+		return
+	}
+	mem := b.getMemory(bPin)
+	if mem != nil {
+		if known, ok := mem.values[bPin.ChipPinNr]; ok {
+			return known, nil
+		}
+		mem.waitForWriteCycle()
+	}
+	params := make(map[string]interface{})
+	params["address"] = bPin.ChipPinNr
+	result := driver.Command("ReadEEPROM")(params).(map[string]interface{})
+	if result["err"] != nil {
+		return 0, result["err"].(error)
+	}
+	val = result["val"].(uint8)
+	if mem != nil {
+		mem.values[bPin.ChipPinNr] = val
+	}
+	return
+}
+
+func (b *Board) getMemory(boardPin *boardpin.Pin) *eeprom {
+	if chip, ok := b.chips[boardPin.ChipID]; ok {
+		return chip.memory
+	}
+	return nil
+}
+
+// waitForWriteCycle sleeps until the last write cycle is finished, it returns immediately when no write is ongoing
+func (m *eeprom) waitForWriteCycle() {
+	if wait := time.Until(m.writeDone); wait > 0 {
+		time.Sleep(wait)
+	}
+}
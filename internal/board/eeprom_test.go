@@ -0,0 +1,150 @@
+package board
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+type countingDriverMock struct {
+	*virtualDriver
+	calls map[string]int
+}
+
+func newEEPROMTestBoard(name string) (*Board, *countingDriverMock) {
+	driver := &countingDriverMock{virtualDriver: newVirtualDriver(), calls: make(map[string]int)}
+	chips := map[string]*chip{"Mem": newEEPROMChip(driver, 0x44)}
+	pins := PinsMap{
+		0: {ChipID: "Mem", ChipPinNr: 0x10, PinType: boardpin.Memory},
+		1: {ChipID: "Mem", ChipPinNr: 0x11, PinType: boardpin.Memory},
+	}
+	return NewBoard(name, chips, pins, "Test"), driver
+}
+
+func TestEEPROMWriteUnchangedValueIsSkipped(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardm, driver := newEEPROMTestBoard("TestEEPROMWriteUnchangedValueIsSkipped")
+	// act
+	err1 := boardm.WriteValue(0, 0x5A)
+	err2 := boardm.WriteValue(0, 0x5A)
+	err3 := boardm.WriteValue(0, 0xA5)
+	val, err4 := boardm.ReadValue(0)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	require.Nil(err4)
+	assert.Equal(uint8(0xA5), val)
+	assert.Equal(2, driver.calls["WriteEEPROM"])
+	assert.Equal(0, driver.calls["ReadEEPROM"])
+}
+
+func TestEEPROMReadOnlyOnce(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardm, driver := newEEPROMTestBoard("TestEEPROMReadOnlyOnce")
+	driver.eeprom[0x11] = 0x33
+	// act
+	val1, err1 := boardm.ReadValue(1)
+	val2, err2 := boardm.ReadValue(1)
+	errWrite := boardm.WriteValue(1, 0x33)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(errWrite)
+	assert.Equal(uint8(0x33), val1)
+	assert.Equal(uint8(0x33), val2)
+	assert.Equal(1, driver.calls["ReadEEPROM"])
+	assert.Equal(0, driver.calls["WriteEEPROM"])
+}
+
+func TestEEPROMWaitsForWriteCycle(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardm, _ := newEEPROMTestBoard("TestEEPROMWaitsForWriteCycle")
+	start := time.Now()
+	// act
+	err1 := boardm.WriteValue(0, 1)
+	err2 := boardm.WriteValue(1, 2)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	assert.GreaterOrEqual(int64(time.Since(start)), int64(eepromWriteCycle))
+}
+
+func TestSetMemoryRange(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardt2 := NewBoardType2io(new(adaptorMock), 0x05, "TestSetMemoryRange")
+	// act
+	err := boardt2.SetMemoryRange(0x20, 0x3F)
+	// assert
+	require.Nil(err)
+	assert.Equal(8+32, len(boardt2.GetPinNumbers()))
+	assert.Equal(32, len(boardt2.GetPinNumbersOfType(boardpin.Memory)))
+	assert.Equal(uint8(0x20), boardt2.pins[8].ChipPinNr)
+	assert.Equal(uint8(0x3F), boardt2.pins[39].ChipPinNr)
+	assert.Equal(chipIDEEPROM, boardt2.pins[39].ChipID)
+	assert.Equal(uint8(0x07), boardPinsType2io[15].ChipPinNr)
+}
+
+func TestSetMemoryRangeNotValidGetsError(t *testing.T) {
+	var rangeTests = map[string]struct {
+		from    uint8
+		to      uint8
+		wantErr string
+	}{
+		"Reserved":    {from: 0x00, to: 0xC0, wantErr: "range 0x00..0xC0 is not valid, allowed is 0x00..0xBF"},
+		"FromAboveTo": {from: 0x10, to: 0x0F, wantErr: "range 0x10..0x0F is not valid"},
+	}
+	for name, rt := range rangeTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			boardt2 := NewBoardType2i(new(adaptorMock), 0x05, "TestSetMemoryRangeNotValidGetsError")
+			// act
+			err := boardt2.SetMemoryRange(rt.from, rt.to)
+			// assert
+			require.NotNil(err)
+			assert.Contains(err.Error(), rt.wantErr)
+			assert.Equal(16, len(boardt2.GetPinNumbers()))
+		})
+	}
+}
+
+func TestSetMemoryRangeWithoutMemoryGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	boardpcf := NewBoardPCF8574i(new(adaptorMock), 0x20, "TestSetMemoryRangeWithoutMemoryGetsError")
+	// act
+	err := boardpcf.SetMemoryRange(0x00, 0x07)
+	// assert
+	assert.NotNil(err)
+}
+
+func TestType2GobotDevicesContainsSharedDriverOnce(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	boardt2 := NewBoardType2o(new(adaptorMock), 0x05, "TestType2GobotDevicesContainsSharedDriverOnce")
+	// act
+	devs := boardt2.GobotDevices()
+	// assert
+	assert.Equal(2, len(boardt2.chips))
+	assert.Equal(1, len(devs))
+	assert.Equal(uint8(0x45), boardt2.chips[chipIDEEPROM].address)
+}
+
+func (d *countingDriverMock) Command(name string) func(map[string]interface{}) interface{} {
+	d.calls[name]++
+	return d.virtualDriver.Command(name)
+}
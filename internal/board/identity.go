@@ -0,0 +1,134 @@
+package board
+
+// The identity is a small descriptor of the board, which is stored in the EEPROM of the board
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : read/write EEPROM of board
+//
+// The descriptor is stored in the reserved area 0xC0..0xFF of the EEPROM:
+// - 0xC0..0xC1 magic "gb"
+// - 0xC2       version of the descriptor format
+// - 0xC3..0xD2 type of board, zero padded
+// - 0xD3..0xF2 name of board, zero padded
+// - 0xF3..0xFE not used, zero
+// - 0xFF       checksum, the sum of all bytes of the descriptor is zero
+//
+// Functions:
+// + write identity to EEPROM
+// + read identity from EEPROM and check for validity
+//
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+const identityStart = eepromReservedStart
+const identitySize = 0x40
+const identityMagic = "gb"
+const identityVersion = 1
+const identityTypeStart = 0x03
+const identityTypeLen = 16
+const identityNameStart = identityTypeStart + identityTypeLen
+const identityNameLen = 32
+
+// Identity is the descriptor of a board, which is stored in the EEPROM
+type Identity struct {
+	Name    string
+	Type    string
+	Version uint8
+}
+
+// WriteIdentity writes the identity to the reserved area of the EEPROM
+func (b *Board) WriteIdentity(identity Identity) (err error) {
+	var data []uint8
+	if data, err = encodeIdentity(identity); err != nil {
+		return
+	}
+	var memChipID string
+	if memChipID, err = b.getMemoryChipID(); err != nil {
+		return
+	}
+	for i, val := range data {
+		bPin := &boardpin.Pin{ChipID: memChipID, ChipPinNr: uint8(identityStart + i)}
+		if err = b.writeEEPROM(bPin, val); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ReadIdentity reads the identity from the reserved area of the EEPROM, "ok" is false when no identity is stored
+func (b *Board) ReadIdentity() (identity Identity, ok bool, err error) {
+	var memChipID string
+	if memChipID, err = b.getMemoryChipID(); err != nil {
+		return
+	}
+	data := make([]uint8, identitySize)
+	for i := range data {
+		bPin := &boardpin.Pin{ChipID: memChipID, ChipPinNr: uint8(identityStart + i)}
+		if data[i], err = b.readEEPROM(bPin); err != nil {
+			return
+		}
+		// stop early for not written EEPROM
+		if i == len(identityMagic)-1 && string(data[:len(identityMagic)]) != identityMagic {
+			return
+		}
+	}
+	return decodeIdentity(data)
+}
+
+// HasMemory returns true when the board provides an EEPROM
+func (b *Board) HasMemory() bool {
+	_, err := b.getMemoryChipID()
+	return err == nil
+}
+
+func (b *Board) getMemoryChipID() (chipID string, err error) {
+	for _, bPin := range b.pins {
+		if bPin.PinTypeIsOneOf(memoryPinTypes) {
+			return bPin.ChipID, nil
+		}
+	}
+	return "", fmt.Errorf("No EEPROM available at board %s", b.name)
+}
+
+func encodeIdentity(identity Identity) (data []uint8, err error) {
+	if len(identity.Type) > identityTypeLen {
+		return nil, fmt.Errorf("Type '%s' is too long for identity, max. %d", identity.Type, identityTypeLen)
+	}
+	if len(identity.Name) > identityNameLen {
+		return nil, fmt.Errorf("Name '%s' is too long for identity, max. %d", identity.Name, identityNameLen)
+	}
+	data = make([]uint8, identitySize)
+	copy(data, identityMagic)
+	data[len(identityMagic)] = identityVersion
+	copy(data[identityTypeStart:], identity.Type)
+	copy(data[identityNameStart:], identity.Name)
+	data[identitySize-1] = -checksum(data[:identitySize-1])
+	return
+}
+
+func decodeIdentity(data []uint8) (identity Identity, ok bool, err error) {
+	if len(data) != identitySize || string(data[:len(identityMagic)]) != identityMagic {
+		return
+	}
+	if checksum(data) != 0 {
+		return identity, false, fmt.Errorf("Checksum error in identity")
+	}
+	identity.Version = data[len(identityMagic)]
+	identity.Type = strings.TrimRight(string(data[identityTypeStart:identityTypeStart+identityTypeLen]), "\x00")
+	identity.Name = strings.TrimRight(string(data[identityNameStart:identityNameStart+identityNameLen]), "\x00")
+	return identity, true, nil
+}
+
+func checksum(data []uint8) (sum uint8) {
+	for _, val := range data {
+		sum += val
+	}
+	return
+}
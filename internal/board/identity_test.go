@@ -0,0 +1,115 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeIdentity(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	identity := Identity{Name: "Station left", Type: "Type2io"}
+	// act
+	data, errEncode := encodeIdentity(identity)
+	decoded, ok, errDecode := decodeIdentity(data)
+	// assert
+	require.Nil(errEncode)
+	require.Nil(errDecode)
+	require.True(ok)
+	assert.Equal(identitySize, len(data))
+	assert.Equal(uint8(0), checksum(data))
+	assert.Equal("Station left", decoded.Name)
+	assert.Equal("Type2io", decoded.Type)
+	assert.Equal(uint8(identityVersion), decoded.Version)
+}
+
+func TestEncodeIdentityTooLongGetsError(t *testing.T) {
+	var encodeTests = map[string]struct {
+		identity Identity
+		wantErr  string
+	}{
+		"TypeTooLong": {identity: Identity{Type: "12345678901234567"}, wantErr: "Type '12345678901234567' is too long"},
+		"NameTooLong": {identity: Identity{Name: "123456789012345678901234567890123"}, wantErr: "is too long for identity, max. 32"},
+	}
+	for name, et := range encodeTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			// act
+			data, err := encodeIdentity(et.identity)
+			// assert
+			require.NotNil(err)
+			assert.Contains(err.Error(), et.wantErr)
+			assert.Nil(data)
+		})
+	}
+}
+
+func TestDecodeIdentity(t *testing.T) {
+	// arrange
+	valid, _ := encodeIdentity(Identity{Name: "B1", Type: "Type2i"})
+	corrupt := append([]uint8{}, valid...)
+	corrupt[identityNameStart] = 'X'
+	empty := make([]uint8, identitySize)
+	for i := range empty {
+		empty[i] = 0xFF
+	}
+	var decodeTests = map[string]struct {
+		data    []uint8
+		wantOk  bool
+		wantErr bool
+	}{
+		"Valid":      {data: valid, wantOk: true},
+		"NotWritten": {data: empty},
+		"WrongSize":  {data: valid[:10]},
+		"Checksum":   {data: corrupt, wantErr: true},
+	}
+	for name, dt := range decodeTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			// act
+			_, ok, err := decodeIdentity(dt.data)
+			// assert
+			assert.Equal(dt.wantOk, ok)
+			assert.Equal(dt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestWriteReadIdentityVirtual8io(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardv := NewBoardVirtual8io("TestWriteReadIdentityVirtual8io")
+	// act
+	_, okBefore, errBefore := boardv.ReadIdentity()
+	errWrite := boardv.WriteIdentity(Identity{Name: "V1", Type: "Virtual8io"})
+	identity, ok, err := boardv.ReadIdentity()
+	// assert
+	require.Nil(errBefore)
+	require.Nil(errWrite)
+	require.Nil(err)
+	assert.False(okBefore)
+	assert.True(ok)
+	assert.Equal("V1", identity.Name)
+	assert.Equal("Virtual8io", identity.Type)
+}
+
+func TestIdentityWithoutMemoryGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	boardpcf := NewBoardPCF8574i(new(adaptorMock), 0x20, "TestIdentityWithoutMemoryGetsError")
+	// act
+	errWrite := boardpcf.WriteIdentity(Identity{Name: "P1"})
+	_, ok, errRead := boardpcf.ReadIdentity()
+	// assert
+	assert.False(boardpcf.HasMemory())
+	assert.NotNil(errWrite)
+	assert.NotNil(errRead)
+	assert.False(ok)
+}
@@ -0,0 +1,92 @@
+package board
+
+// The input port is a snapshot of all GPIO of a chip, which is read once per cycle
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: board
+// Call       : some functions from gobot-i2c (connection)
+//
+// Reading the whole port needs only one i2c transaction, regardless of the count of inputs. The snapshot is valid
+// until the inputs of the board are expired (next cycle) or an output of the same chip is written.
+//
+// Functions:
+// + read the port of a chip on first request in cycle
+// + serve all other requests of the cycle from snapshot
+//
+
+import (
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+type inputPort struct {
+	read  func() (value uint16, err error)
+	value uint16
+	valid bool
+}
+
+// readPinTypes are all types of GPIO pins, which can be read
+var readPinTypes = []boardpin.PinType{boardpin.Binary, boardpin.BinaryR, boardpin.NBinary, boardpin.NBinaryR}
+
+// newQuasiBidirectionalPort creates a snapshot reader for chips like PCA9501 or PCF8574, there is only one register for
+// input and output. Inputs can only be read when the output latch is high, so all input pins needs to be set high
+// again, when an input was read as low. This is done only in this case to save transactions.
+func newQuasiBidirectionalPort(chipCon *chipConnection, inputMask uint8) *inputPort {
+	return &inputPort{read: func() (value uint16, err error) {
+		var connection i2c.Connection
+		if connection, err = chipCon.get(); err != nil {
+			return
+		}
+		var val uint8
+		if val, err = connection.ReadByte(); err != nil {
+			return
+		}
+		if val&inputMask != inputMask {
+			if err = connection.WriteByte(val | inputMask); err != nil {
+				return
+			}
+			if val, err = connection.ReadByte(); err != nil {
+				return
+			}
+		}
+		return uint16(val), nil
+	}}
+}
+
+// newRegisterPort creates a snapshot reader for chips with separate register for inputs, e.g. MCP23017
+func newRegisterPort(chipCon *chipConnection, register uint8) *inputPort {
+	return &inputPort{read: func() (value uint16, err error) {
+		var connection i2c.Connection
+		if connection, err = chipCon.get(); err != nil {
+			return
+		}
+		return connection.ReadWordData(register)
+	}}
+}
+
+// pin gets the value of the given chip pin, the port is read when the snapshot is not valid
+func (p *inputPort) pin(chipPinNr uint8) (val uint8, err error) {
+	if !p.valid {
+		if p.value, err = p.read(); err != nil {
+			return
+		}
+		p.valid = true
+	}
+	return uint8(p.value>>chipPinNr) & 0x01, nil
+}
+
+func (p *inputPort) expire() {
+	p.valid = false
+}
+
+// inputMask gets the mask of all readable pins of the given chip
+func (pm PinsMap) inputMask(chipID string) (mask uint16) {
+	for _, bPin := range pm {
+		if bPin.ChipID == chipID && bPin.PinTypeIsOneOf(readPinTypes) {
+			mask |= 1 << bPin.ChipPinNr
+		}
+	}
+	return
+}
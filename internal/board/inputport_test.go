@@ -0,0 +1,195 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+type portConnectorMock struct {
+	con *portConnectionMock
+}
+
+type portConnectionMock struct {
+	latch     uint8
+	levels    uint8
+	word      uint16
+	reads     int
+	writes    int
+	wordReads int
+}
+
+func TestInputPortReadsOncePerCycle(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	reads := 0
+	port := &inputPort{read: func() (uint16, error) {
+		reads++
+		return 0x0005, nil
+	}}
+	// act
+	val0, err0 := port.pin(0)
+	val1, err1 := port.pin(1)
+	val2, err2 := port.pin(2)
+	readsInCycle := reads
+	port.expire()
+	_, err3 := port.pin(0)
+	// assert
+	require.Nil(err0)
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	assert.Equal(uint8(1), val0)
+	assert.Equal(uint8(0), val1)
+	assert.Equal(uint8(1), val2)
+	assert.Equal(1, readsInCycle)
+	assert.Equal(2, reads)
+}
+
+func TestQuasiBidirectionalPort(t *testing.T) {
+	var quasiTests = map[string]struct {
+		latch      uint8
+		levels     uint8
+		wantValue  uint16
+		wantReads  int
+		wantWrites int
+	}{
+		"AllInputsHigh":       {latch: 0xF0, levels: 0xFF, wantValue: 0xF0, wantReads: 1},
+		"InputLowByLevel":     {latch: 0xF0, levels: 0xDF, wantValue: 0xD0, wantReads: 2, wantWrites: 1},
+		"InputLowByLatchOnly": {latch: 0x70, levels: 0xFF, wantValue: 0xF0, wantReads: 2, wantWrites: 1},
+	}
+	for name, qt := range quasiTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			connector := &portConnectorMock{con: &portConnectionMock{latch: qt.latch, levels: qt.levels}}
+			port := newQuasiBidirectionalPort(newChipConnection(connector, 0x04), 0xF0)
+			// act
+			value, err := port.read()
+			// assert
+			require.Nil(err)
+			assert.Equal(qt.wantValue, value)
+			assert.Equal(qt.wantReads, connector.con.reads)
+			assert.Equal(qt.wantWrites, connector.con.writes)
+		})
+	}
+}
+
+func TestRegisterPort(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	connector := &portConnectorMock{con: &portConnectionMock{word: 0x8001}}
+	port := newRegisterPort(newChipConnection(connector, 0x20), mcp23017RegGPIO)
+	// act
+	val0, err0 := port.pin(0)
+	val15, err15 := port.pin(15)
+	val8, err8 := port.pin(8)
+	// assert
+	require.Nil(err0)
+	require.Nil(err15)
+	require.Nil(err8)
+	assert.Equal(uint8(1), val0)
+	assert.Equal(uint8(1), val15)
+	assert.Equal(uint8(0), val8)
+	assert.Equal(1, connector.con.wordReads)
+}
+
+func TestInputPortWithoutConnectionGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	portNoAdaptor := newQuasiBidirectionalPort(newChipConnection(nil, 0x04), 0xFF)
+	portNoConnection := newRegisterPort(newChipConnection(new(adaptorMock), 0x20), mcp23017RegGPIO)
+	// act
+	_, err1 := portNoAdaptor.pin(0)
+	_, err2 := portNoConnection.pin(0)
+	// assert
+	assert.NotNil(err1)
+	assert.NotNil(err2)
+}
+
+func TestInputMask(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act & assert
+	assert.Equal(uint16(0x00FF), boardPinsType2i.inputMask(chipID))
+	assert.Equal(uint16(0x0000), boardPinsType2o.inputMask(chipID))
+	assert.Equal(uint16(0x00F0), boardPinsType2io.inputMask(chipID))
+	assert.Equal(uint16(0x0000), boardPinsType2io.inputMask("unknownChip"))
+}
+
+func TestReadValueUsesSnapshotUntilExpired(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardv := NewBoardVirtual8io("TestReadValueUsesSnapshotUntilExpired")
+	driver := boardv.GobotDevices()[0].(DriverOperations)
+	pinParams := map[string]interface{}{"pin": uint8(5), "val": uint8(1)}
+	// act
+	valBefore, err1 := boardv.ReadValue(5)
+	driver.Command("WriteGPIO")(pinParams)
+	valSameCycle, err2 := boardv.ReadValue(5)
+	boardv.ExpireInputs()
+	valNextCycle, err3 := boardv.ReadValue(5)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	assert.Equal(uint8(0), valBefore)
+	assert.Equal(uint8(0), valSameCycle)
+	assert.Equal(uint8(1), valNextCycle)
+}
+
+func TestWriteValueExpiresSnapshotOfChip(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	boardv := NewBoardVirtual8io("TestWriteValueExpiresSnapshotOfChip")
+	// act
+	valBefore, err1 := boardv.ReadValue(2)
+	err2 := boardv.WriteValue(2, 1)
+	valAfter, err3 := boardv.ReadValue(2)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	assert.Equal(uint8(0), valBefore)
+	assert.Equal(uint8(1), valAfter)
+	assert.Equal(boardpin.Binary, boardv.pins[2].PinType)
+}
+
+func (a *portConnectorMock) GetConnection(address int, bus int) (device i2c.Connection, err error) {
+	return a.con, nil
+}
+func (a *portConnectorMock) GetDefaultBus() int { return 0 }
+
+func (c *portConnectionMock) Read(b []byte) (n int, err error)  { return }
+func (c *portConnectionMock) Write(b []byte) (n int, err error) { return }
+func (c *portConnectionMock) Close() (err error)                { return }
+func (c *portConnectionMock) ReadByte() (val byte, err error) {
+	c.reads++
+	return c.latch & c.levels, nil
+}
+func (c *portConnectionMock) ReadByteData(reg uint8) (val uint8, err error) { return }
+func (c *portConnectionMock) ReadWordData(reg uint8) (val uint16, err error) {
+	c.wordReads++
+	return c.word, nil
+}
+func (c *portConnectionMock) WriteByte(val byte) (err error) {
+	c.writes++
+	c.latch = val
+	return
+}
+func (c *portConnectionMock) WriteByteData(reg uint8, val uint8) (err error) { return }
+func (c *portConnectionMock) WriteWordData(reg uint8, val uint16) (err error) {
+	c.writes++
+	c.word = val
+	return
+}
+func (c *portConnectionMock) WriteBlockData(reg uint8, b []byte) (err error) { return }
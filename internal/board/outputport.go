@@ -0,0 +1,120 @@
+package board
+
+// The output port is a shadow copy of the output register of a chip
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: board
+// Call       : some functions from gobot-i2c (connection)
+//
+// A write of one pin is done as read-modify-write on the shadow, so a write of an unchanged value needs no i2c
+// transaction. The shadow is initialized by reading the register before the first write.
+// When coalescing is active, all changes are written together by flush, which should be called at end of each cycle.
+// Coalescing is not suitable for pulses within one cycle (e.g. turnouts), because the pulse would be lost.
+//
+// Functions:
+// + write a pin only when changed
+// + write all changes of a chip in one transaction (coalescing)
+//
+
+import (
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+// register OLATA for bank 0, the next register is OLATB
+const mcp23017RegOLAT = 0x14
+
+type outputPort struct {
+	read     func() (value uint16, err error)
+	write    func(value uint16) (err error)
+	value    uint16
+	known    bool
+	pending  bool
+	coalesce bool
+}
+
+// newQuasiBidirectionalOutputPort creates a shadow for chips like PCA9501 or PCF8574, all input pins will be kept
+// high on write, otherwise the input can not be read anymore
+func newQuasiBidirectionalOutputPort(chipCon *chipConnection, inputMask uint8) *outputPort {
+	return &outputPort{
+		read: func() (value uint16, err error) {
+			var connection i2c.Connection
+			if connection, err = chipCon.get(); err != nil {
+				return
+			}
+			var val uint8
+			val, err = connection.ReadByte()
+			return uint16(val), err
+		},
+		write: func(value uint16) (err error) {
+			var connection i2c.Connection
+			if connection, err = chipCon.get(); err != nil {
+				return
+			}
+			return connection.WriteByte(uint8(value) | inputMask)
+		},
+	}
+}
+
+// newRegisterOutputPort creates a shadow for chips with separate output register, e.g. MCP23017
+func newRegisterOutputPort(chipCon *chipConnection, register uint8) *outputPort {
+	return &outputPort{
+		read: func() (value uint16, err error) {
+			var connection i2c.Connection
+			if connection, err = chipCon.get(); err != nil {
+				return
+			}
+			return connection.ReadWordData(register)
+		},
+		write: func(value uint16) (err error) {
+			var connection i2c.Connection
+			if connection, err = chipCon.get(); err != nil {
+				return
+			}
+			return connection.WriteWordData(register, value)
+		},
+	}
+}
+
+// pin sets the value of the given chip pin in shadow and writes the port, when the value has changed
+func (p *outputPort) pin(chipPinNr uint8, val uint8) (err error) {
+	if !p.known {
+		if p.value, err = p.read(); err != nil {
+			return
+		}
+		p.known = true
+	}
+	newValue := p.value
+	if val == 0 {
+		newValue &^= 1 << chipPinNr
+	} else {
+		newValue |= 1 << chipPinNr
+	}
+	if newValue == p.value {
+		return
+	}
+	p.value = newValue
+	p.pending = true
+	if p.coalesce {
+		return
+	}
+	return p.flush()
+}
+
+// expire marks the shadow as unknown, so it will be initialized by reading the register before the next write
+func (p *outputPort) expire() {
+	p.known = false
+}
+
+// flush writes the shadow to the port, when there are pending changes
+func (p *outputPort) flush() (err error) {
+	if !p.pending {
+		return
+	}
+	p.pending = false
+	if err = p.write(p.value); err != nil {
+		// the state of the chip is unknown now
+		p.known = false
+	}
+	return
+}
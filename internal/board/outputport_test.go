@@ -0,0 +1,140 @@
+package board
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outputPortMock struct {
+	register uint16
+	reads    int
+	writes   []uint16
+	writeErr error
+}
+
+func newOutputPortWithMock(register uint16) (*outputPort, *outputPortMock) {
+	mock := &outputPortMock{register: register}
+	port := &outputPort{
+		read: func() (uint16, error) {
+			mock.reads++
+			return mock.register, nil
+		},
+		write: func(value uint16) error {
+			if mock.writeErr != nil {
+				return mock.writeErr
+			}
+			mock.writes = append(mock.writes, value)
+			mock.register = value
+			return nil
+		},
+	}
+	return port, mock
+}
+
+func TestOutputPortReadModifyWrite(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	port, mock := newOutputPortWithMock(0x0081)
+	// act
+	err1 := port.pin(2, 1)
+	err2 := port.pin(0, 0)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	assert.Equal(1, mock.reads)
+	assert.Equal([]uint16{0x0085, 0x0084}, mock.writes)
+}
+
+func TestOutputPortSkipsUnchangedValue(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	port, mock := newOutputPortWithMock(0x0000)
+	// act
+	err1 := port.pin(3, 1)
+	err2 := port.pin(3, 1)
+	err3 := port.pin(4, 0)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	assert.Equal([]uint16{0x0008}, mock.writes)
+}
+
+func TestOutputPortCoalescedWrites(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	port, mock := newOutputPortWithMock(0x0000)
+	port.coalesce = true
+	// act
+	err1 := port.pin(0, 1)
+	err2 := port.pin(1, 1)
+	err3 := port.pin(9, 1)
+	writesBeforeFlush := len(mock.writes)
+	err4 := port.flush()
+	err5 := port.flush()
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	require.Nil(err4)
+	require.Nil(err5)
+	assert.Equal(0, writesBeforeFlush)
+	assert.Equal([]uint16{0x0203}, mock.writes)
+}
+
+func TestOutputPortWriteErrorForcesReadOfRegister(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	port, mock := newOutputPortWithMock(0x0000)
+	mock.writeErr = fmt.Errorf("bus error")
+	// act
+	err1 := port.pin(0, 1)
+	mock.writeErr = nil
+	err2 := port.pin(1, 1)
+	// assert
+	require.NotNil(err1)
+	require.Nil(err2)
+	assert.Equal(2, mock.reads)
+	assert.Equal([]uint16{0x0002}, mock.writes)
+}
+
+func TestQuasiBidirectionalOutputPortKeepsInputsHigh(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	connector := &portConnectorMock{con: &portConnectionMock{latch: 0xFF, levels: 0x7F}}
+	port := newQuasiBidirectionalOutputPort(newChipConnection(connector, 0x04), 0xF0)
+	// act
+	err := port.pin(0, 0)
+	// assert
+	require.Nil(err)
+	assert.Equal(uint8(0xFE), connector.con.latch)
+}
+
+func TestBoardFlushOutputs(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	connector := &portConnectorMock{con: &portConnectionMock{latch: 0xFF, levels: 0xFF}}
+	boardt2 := NewBoardType2o(connector, 0x04, "TestBoardFlushOutputs")
+	boardt2.SetCoalescedWrites(true)
+	// act
+	err1 := boardt2.WriteValue(0, 0)
+	err2 := boardt2.WriteValue(1, 0)
+	writesBeforeFlush := connector.con.writes
+	err3 := boardt2.FlushOutputs()
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	assert.Equal(0, writesBeforeFlush)
+	assert.Equal(1, connector.con.writes)
+	assert.Equal(uint8(0xFC), connector.con.latch)
+}
@@ -0,0 +1,175 @@
+package boarddefinition
+
+// A boarddefinition describes the chips and pins of a board, so a custom board can be used without code changes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+	"github.com/gen2thomas/gobrail/internal/errwrap"
+	"github.com/gen2thomas/gobrail/internal/jsonrecipe"
+)
+
+// Schema is for json validation
+var Schema = "./schemas/boarddefinition.schema.json"
+
+type driverKind uint8
+
+const (
+	// DriverUnknown is for fall back (must be the first entry)
+	DriverUnknown driverKind = iota
+	// PCA9501 is a chip with 8 quasi bidirectional GPIO and 256 byte EEPROM
+	PCA9501
+	// PCF8574 is a chip with 8 quasi bidirectional GPIO, also used for PCF8574A
+	PCF8574
+	// MCP23017 is a chip with 16 GPIO, the direction is given by the pin type
+	MCP23017
+	// PCA9685 is a chip with 16 PWM outputs
+	PCA9685
+)
+
+// DriverMap is the string representation to the underlying "driverKind"
+var DriverMap = map[string]driverKind{
+	"DriverUnknown": DriverUnknown, "PCA9501": PCA9501, "PCF8574": PCF8574, "MCP23017": MCP23017, "PCA9685": PCA9685,
+}
+
+// gpioPinTypes are all pin types usable for GPIO, the direction is free for quasi bidirectional chips
+var gpioPinTypes = []boardpin.PinType{
+	boardpin.Binary, boardpin.BinaryR, boardpin.BinaryW, boardpin.NBinary, boardpin.NBinaryR, boardpin.NBinaryW,
+}
+
+// directedPinTypes are all pin types usable for chips, where the direction needs to be configured
+var directedPinTypes = []boardpin.PinType{boardpin.BinaryR, boardpin.BinaryW, boardpin.NBinaryR, boardpin.NBinaryW}
+
+var memoryPinTypes = []boardpin.PinType{boardpin.Memory, boardpin.MemoryR, boardpin.MemoryW}
+
+var pwmPinTypes = []boardpin.PinType{boardpin.Analog, boardpin.AnalogW}
+
+// Chip describes a chip on the board, the address offset is added to the address of the board recipe
+type Chip struct {
+	ID            string `json:"ID"`
+	Driver        string `json:"Driver"`
+	AddressOffset uint8  `json:"AddressOffset"`
+}
+
+// Pin describes a pin of the board and the connection to the chip
+type Pin struct {
+	BoardPinNr uint8  `json:"BoardPinNr"`
+	ChipID     string `json:"ChipID"`
+	ChipPinNr  uint8  `json:"ChipPinNr"`
+	PinType    string `json:"PinType"`
+	MinVal     uint8  `json:"MinVal,omitempty"`
+	MaxVal     uint8  `json:"MaxVal,omitempty"`
+}
+
+// Definition is the description of all chips and pins of a board type
+type Definition struct {
+	Type  string `json:"Type"`
+	Chips []Chip `json:"Chips"`
+	Pins  []Pin  `json:"Pins"`
+}
+
+// ReadDefinition is parsing json board definition
+func ReadDefinition(definitionFile string) (definition Definition, err error) {
+	definitionFile, err = jsonrecipe.PrepareAndValidate(Schema, definitionFile)
+	if err != nil {
+		return
+	}
+
+	var jsonFile *os.File
+	var byteValue []byte
+	jsonFile, err = os.Open(definitionFile)
+	if err == nil {
+		byteValue, err = ioutil.ReadAll(jsonFile)
+	}
+	if err == nil {
+		err = json.Unmarshal(byteValue, &definition)
+	}
+	err = errwrap.Wrap(err, jsonFile.Close())
+	if err == nil {
+		err = definition.Verify()
+	}
+	if err != nil {
+		err = fmt.Errorf("%s for file %s", err.Error(), definitionFile)
+	}
+	return
+}
+
+// Verify is checking that string values are parsable to the corresponding type and all pins fit to the chips
+func (d Definition) Verify() (err error) {
+	if d.Type == "" {
+		return fmt.Errorf("The type of the board definition is missing")
+	}
+	if len(d.Chips) == 0 {
+		return fmt.Errorf("The board definition '%s' contains no chip", d.Type)
+	}
+	chips := make(map[string]Chip)
+	offsets := make(map[uint8]string)
+	for _, chip := range d.Chips {
+		if _, ok := DriverMap[chip.Driver]; !ok || chip.Driver == "DriverUnknown" {
+			return fmt.Errorf("The given driver '%s' of chip '%s' is unknown", chip.Driver, chip.ID)
+		}
+		if _, ok := chips[chip.ID]; ok {
+			return fmt.Errorf("The chip '%s' is defined more than once", chip.ID)
+		}
+		if otherID, ok := offsets[chip.AddressOffset]; ok {
+			return fmt.Errorf("The chips '%s' and '%s' have the same address offset %d", otherID, chip.ID, chip.AddressOffset)
+		}
+		chips[chip.ID] = chip
+		offsets[chip.AddressOffset] = chip.ID
+	}
+	boardPinNumbers := make(boardpin.PinNumbers)
+	for _, pin := range d.Pins {
+		if _, ok := boardPinNumbers[pin.BoardPinNr]; ok {
+			return fmt.Errorf("The board pin %d is defined more than once", pin.BoardPinNr)
+		}
+		boardPinNumbers[pin.BoardPinNr] = struct{}{}
+		chip, ok := chips[pin.ChipID]
+		if !ok {
+			return fmt.Errorf("The chip '%s' of board pin %d is not defined", pin.ChipID, pin.BoardPinNr)
+		}
+		if err = verifyPin(pin, DriverMap[chip.Driver]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// verifyPin checks the pin type and the chip pin number is supported by the driver of the chip
+func verifyPin(pin Pin, driver driverKind) (err error) {
+	var pinType boardpin.PinType
+	if pinType, err = boardpin.ParsePinType(pin.PinType); err != nil {
+		return fmt.Errorf("%s at board pin %d", err.Error(), pin.BoardPinNr)
+	}
+	bPin := boardpin.Pin{PinType: pinType}
+	var maxChipPinNr uint8
+	switch {
+	case driver == PCA9501 && bPin.PinTypeIsOneOf(memoryPinTypes):
+		// 0xC0..0xFF is reserved for the identity of the board
+		maxChipPinNr = 0xBF
+	case (driver == PCA9501 || driver == PCF8574) && bPin.PinTypeIsOneOf(gpioPinTypes):
+		maxChipPinNr = 7
+	case driver == MCP23017 && bPin.PinTypeIsOneOf(directedPinTypes):
+		maxChipPinNr = 15
+	case driver == PCA9685 && bPin.PinTypeIsOneOf(pwmPinTypes):
+		maxChipPinNr = 15
+		if pin.MinVal > pin.MaxVal || pin.MaxVal == 0 {
+			return fmt.Errorf("The range %d..%d of board pin %d is not valid", pin.MinVal, pin.MaxVal, pin.BoardPinNr)
+		}
+	default:
+		return fmt.Errorf("The pin type '%s' of board pin %d is not supported by chip '%s'", pin.PinType, pin.BoardPinNr,
+			pin.ChipID)
+	}
+	if pin.ChipPinNr > maxChipPinNr {
+		return fmt.Errorf("The chip pin %d of board pin %d is out of range 0..%d", pin.ChipPinNr, pin.BoardPinNr,
+			maxChipPinNr)
+	}
+	return
+}
+
+func (d Definition) String() string {
+	return fmt.Sprintf("Type: %s, Chips: %d, Pins: %d", d.Type, len(d.Chips), len(d.Pins))
+}
@@ -0,0 +1,132 @@
+package boarddefinition
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const definitionsBase = "../../test/data/"
+
+type verifyTest struct {
+	definition Definition
+	wantErr    string
+}
+
+func TestVerify(t *testing.T) {
+	gpioChip := []Chip{{ID: "GPIO", Driver: "PCA9501"}}
+	var verifyTests = map[string]verifyTest{
+		"NoError": {
+			definition: Definition{Type: "Carrier", Chips: gpioChip, Pins: []Pin{
+				{BoardPinNr: 0, ChipID: "GPIO", ChipPinNr: 7, PinType: "Binary"},
+				{BoardPinNr: 1, ChipID: "GPIO", ChipPinNr: 0xBF, PinType: "MemoryR"},
+			}},
+		},
+		"TypeMissing": {definition: Definition{Chips: gpioChip}, wantErr: "type of the board definition is missing"},
+		"NoChip":      {definition: Definition{Type: "Carrier"}, wantErr: "contains no chip"},
+		"UnknownDriver": {
+			definition: Definition{Type: "Carrier", Chips: []Chip{{ID: "GPIO", Driver: "PCA9555"}}},
+			wantErr:    "driver 'PCA9555' of chip 'GPIO' is unknown",
+		},
+		"DriverUnknown": {
+			definition: Definition{Type: "Carrier", Chips: []Chip{{ID: "GPIO", Driver: "DriverUnknown"}}},
+			wantErr:    "driver 'DriverUnknown' of chip 'GPIO' is unknown",
+		},
+		"ChipTwice": {
+			definition: Definition{Type: "Carrier", Chips: []Chip{
+				{ID: "GPIO", Driver: "PCA9501"}, {ID: "GPIO", Driver: "PCF8574", AddressOffset: 1}}},
+			wantErr: "chip 'GPIO' is defined more than once",
+		},
+		"SameAddressOffset": {
+			definition: Definition{Type: "Carrier", Chips: []Chip{
+				{ID: "GPIO1", Driver: "PCA9501", AddressOffset: 2}, {ID: "GPIO2", Driver: "PCF8574", AddressOffset: 2}}},
+			wantErr: "'GPIO1' and 'GPIO2' have the same address offset 2",
+		},
+		"BoardPinTwice": {
+			definition: Definition{Type: "Carrier", Chips: gpioChip, Pins: []Pin{
+				{BoardPinNr: 3, ChipID: "GPIO", ChipPinNr: 0, PinType: "Binary"},
+				{BoardPinNr: 3, ChipID: "GPIO", ChipPinNr: 1, PinType: "Binary"},
+			}},
+			wantErr: "board pin 3 is defined more than once",
+		},
+		"ChipOfPinNotDefined": {
+			definition: Definition{Type: "Carrier", Chips: gpioChip, Pins: []Pin{
+				{BoardPinNr: 0, ChipID: "PWM", PinType: "AnalogW"}}},
+			wantErr: "chip 'PWM' of board pin 0 is not defined",
+		},
+		"UnknownPinType": {
+			definition: Definition{Type: "Carrier", Chips: gpioChip, Pins: []Pin{
+				{BoardPinNr: 4, ChipID: "GPIO", PinType: "Digital"}}},
+			wantErr: "pin type 'Digital' is unknown at board pin 4",
+		},
+		"ChipPinOutOfRange": {
+			definition: Definition{Type: "Carrier", Chips: gpioChip, Pins: []Pin{
+				{BoardPinNr: 0, ChipID: "GPIO", ChipPinNr: 8, PinType: "NBinaryR"}}},
+			wantErr: "chip pin 8 of board pin 0 is out of range 0..7",
+		},
+		"MemoryReserved": {
+			definition: Definition{Type: "Carrier", Chips: gpioChip, Pins: []Pin{
+				{BoardPinNr: 0, ChipID: "GPIO", ChipPinNr: 0xC0, PinType: "Memory"}}},
+			wantErr: "chip pin 192 of board pin 0 is out of range 0..191",
+		},
+		"MemoryNotSupported": {
+			definition: Definition{Type: "Carrier", Chips: []Chip{{ID: "GPIO", Driver: "PCF8574"}}, Pins: []Pin{
+				{BoardPinNr: 0, ChipID: "GPIO", PinType: "Memory"}}},
+			wantErr: "pin type 'Memory' of board pin 0 is not supported by chip 'GPIO'",
+		},
+		"DirectionMissing": {
+			definition: Definition{Type: "Carrier", Chips: []Chip{{ID: "Ports", Driver: "MCP23017"}}, Pins: []Pin{
+				{BoardPinNr: 0, ChipID: "Ports", ChipPinNr: 15, PinType: "BinaryW"},
+				{BoardPinNr: 1, ChipID: "Ports", ChipPinNr: 14, PinType: "Binary"},
+			}},
+			wantErr: "pin type 'Binary' of board pin 1 is not supported by chip 'Ports'",
+		},
+		"AnalogRangeNotValid": {
+			definition: Definition{Type: "Carrier", Chips: []Chip{{ID: "PWM", Driver: "PCA9685"}}, Pins: []Pin{
+				{BoardPinNr: 0, ChipID: "PWM", PinType: "AnalogW", MinVal: 20, MaxVal: 10}}},
+			wantErr: "range 20..10 of board pin 0 is not valid",
+		},
+		"AnalogMaxValMissing": {
+			definition: Definition{Type: "Carrier", Chips: []Chip{{ID: "PWM", Driver: "PCA9685"}}, Pins: []Pin{
+				{BoardPinNr: 0, ChipID: "PWM", PinType: "AnalogW"}}},
+			wantErr: "range 0..0 of board pin 0 is not valid",
+		},
+	}
+	for name, vt := range verifyTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			// act
+			err := vt.definition.Verify()
+			// assert
+			if vt.wantErr == "" {
+				assert.Nil(err)
+			} else {
+				require.NotNil(err)
+				assert.Contains(err.Error(), vt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadDefinition(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	oldSchema := Schema
+	Schema, _ = filepath.Abs("../../schemas/boarddefinition.schema.json")
+	defer func() { Schema = oldSchema }()
+	definitionFile := definitionsBase + "boarddefinitions/carrier_2xPCA9501.json"
+	// act
+	definition, err := ReadDefinition(definitionFile)
+	// assert
+	require.Nil(err)
+	assert.Equal("Carrier2xPCA9501", definition.Type)
+	assert.Equal(2, len(definition.Chips))
+	assert.Equal(uint8(1), definition.Chips[1].AddressOffset)
+	assert.Equal(20, len(definition.Pins))
+	assert.Equal("NBinaryR", definition.Pins[0].PinType)
+}
@@ -55,6 +55,14 @@ var PinTypeMsgMap = map[PinType]string{
 	MemoryW:  "MemoryW (EEPROM address write-only)",
 }
 
+// PinTypeMap is the string representation to the underlying "PinType", e.g. used in board definitions
+var PinTypeMap = map[string]PinType{
+	"Binary": Binary, "BinaryR": BinaryR, "BinaryW": BinaryW,
+	"NBinary": NBinary, "NBinaryR": NBinaryR, "NBinaryW": NBinaryW,
+	"Analog": Analog, "AnalogR": AnalogR, "AnalogW": AnalogW,
+	"Memory": Memory, "MemoryR": MemoryR, "MemoryW": MemoryW,
+}
+
 // Pin is the description of a board pin
 type Pin struct {
 	ChipID    string
@@ -80,6 +88,14 @@ type Output struct {
 	WriteValue func(value uint8) (err error)
 }
 
+// Storage describes a memory pin for reading and writing values, e.g. to keep the state of a device
+type Storage struct {
+	BoardID    string
+	BoardPinNr uint8
+	ReadValue  func() (value uint8, err error)
+	WriteValue func(value uint8) (err error)
+}
+
 // PinNumbers is used to store numbers, e.g. as list of free or used board pins
 type PinNumbers map[uint8]struct{}
 
@@ -94,6 +110,15 @@ func (p Pin) PinTypeIsOneOf(pinTypes []PinType) bool {
 	return false
 }
 
+// ParsePinType gets the pin type of the given string representation, e.g. "NBinaryR"
+func ParsePinType(pinTypeTxt string) (pinType PinType, err error) {
+	var ok bool
+	if pinType, ok = PinTypeMap[pinTypeTxt]; !ok {
+		err = fmt.Errorf("The given pin type '%s' is unknown", pinTypeTxt)
+	}
+	return
+}
+
 func (pt PinType) String() (str string) {
 	if str, ok := PinTypeMsgMap[pt]; ok {
 		return str
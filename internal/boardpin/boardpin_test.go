@@ -1,6 +1,7 @@
 package boardpin_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -69,6 +70,28 @@ func TestUnknownPinTypeToString(t *testing.T) {
 	assert.Contains(strMsg, "Unknown")
 }
 
+func TestParsePinType(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	for _, pinType := range allpinTypes {
+		// act
+		parsed, err := ParsePinType(strings.Fields(pinType.String())[0])
+		// assert
+		assert.Nil(err)
+		assert.Equal(pinType, parsed)
+	}
+}
+
+func TestParseUnknownPinTypeGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act
+	_, err := ParsePinType("Digital")
+	// assert
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "pin type 'Digital' is unknown")
+}
+
 func TestPinNumbersToString(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
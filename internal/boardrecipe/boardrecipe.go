@@ -26,18 +26,53 @@ const (
 	Type2o
 	// Type2io is the board with a single PCA9501 with 4 inputs and 4 amplified outputs
 	Type2io
+	// Virtual8io is a board without hardware, 8 inputs/outputs and memory are simulated
+	Virtual8io
+	// PCF8574i is a board with a single PCF8574 or PCF8574A with 8 inputs
+	PCF8574i
+	// PCF8574o is a board with a single PCF8574 or PCF8574A with 8 low active outputs
+	PCF8574o
+	// PCF8574io is a board with a single PCF8574 or PCF8574A with 4 low active outputs and 4 inputs
+	PCF8574io
+	// MCP23017 is a board with a single MCP23017 with 16 outputs, each pin can be configured as input
+	MCP23017
+	// PCA9685 is a board with a single PCA9685 with 16 PWM outputs
+	PCA9685
+	// Custom is a board with chips and pins given by a board definition file
+	Custom
+	// HostGPIO is the GPIO header of the host with 17 outputs, each pin can be configured as input
+	HostGPIO
 )
 
+// configurablePins is the count of pins for all types, where the direction of each pin can be configured
+var configurablePins = map[boardType]uint8{MCP23017: 16, HostGPIO: 17}
+
+// memoryTypes are all types, where the range of the memory can be configured, for custom boards it depends on the
+// board definition
+var memoryTypes = map[boardType]bool{Type2i: true, Type2o: true, Type2io: true, Virtual8io: true, Custom: true}
+
+// memoryReservedStart is the begin of the reserved area of the EEPROM, which can not be used for memory pins
+const memoryReservedStart = 0xC0
+
 // TypeMap is the string representation to the underlying "boardType"
 var TypeMap = map[string]boardType{
 	"TypUnknown": TypUnknown, "Type2i": Type2i, "Type2o": Type2o, "Type2io": Type2io,
+	"Virtual8io": Virtual8io, "PCF8574i": PCF8574i, "PCF8574o": PCF8574o, "PCF8574io": PCF8574io,
+	"MCP23017": MCP23017, "PCA9685": PCA9685, "Custom": Custom,
+	"HostGPIO": HostGPIO,
 }
 
 // Ingredients is a short description to create a new board
 type Ingredients struct {
-	Name        string `json:"Name"`
-	Type        string `json:"Type"`
-	ChipDevAddr uint8  `json:"ChipDevAddr"`
+	Name        string  `json:"Name"`
+	Type        string  `json:"Type"`
+	ChipDevAddr uint8   `json:"ChipDevAddr"`
+	InputPins   []uint8 `json:"InputPins,omitempty"`
+	MuxAddr     uint8   `json:"MuxAddr,omitempty"`
+	MuxChannel  uint8   `json:"MuxChannel,omitempty"`
+	Definition  string  `json:"Definition,omitempty"`
+	MemoryFrom  uint8   `json:"MemoryFrom,omitempty"`
+	MemoryTo    uint8   `json:"MemoryTo,omitempty"` // 0 means the default range, so a range ends at 1 or above
 }
 
 // ReadIngredients is parsing json board description to a board recipe
@@ -69,12 +104,103 @@ func ReadIngredients(boardFile string) (recipe Ingredients, err error) {
 // Verify is checking that string values are parsable to the corresponding type
 func (r Ingredients) Verify() (err error) {
 	// check for type string is known
-	if _, ok := TypeMap[r.Type]; !ok {
-		err = fmt.Errorf("The given type '%s' is unknown", r.Type)
+	bType, ok := TypeMap[r.Type]
+	if !ok {
+		return fmt.Errorf("The given type '%s' is unknown", r.Type)
+	}
+	// check for valid address of PCF8574 (0x20..0x27) or PCF8574A (0x38..0x3F)
+	if bType == PCF8574i || bType == PCF8574o || bType == PCF8574io {
+		if !(r.ChipDevAddr >= 0x20 && r.ChipDevAddr <= 0x27) && !(r.ChipDevAddr >= 0x38 && r.ChipDevAddr <= 0x3F) {
+			err = fmt.Errorf("The given address 0x%02X is not valid for type '%s'", r.ChipDevAddr, r.Type)
+		}
+	}
+	if bType == MCP23017 && !(r.ChipDevAddr >= 0x20 && r.ChipDevAddr <= 0x27) {
+		err = fmt.Errorf("The given address 0x%02X is not valid for type '%s'", r.ChipDevAddr, r.Type)
+	}
+	// check for valid address of PCA9685 (0x40..0x7F)
+	if bType == PCA9685 && !(r.ChipDevAddr >= 0x40 && r.ChipDevAddr <= 0x7F) {
+		err = fmt.Errorf("The given address 0x%02X is not valid for type '%s'", r.ChipDevAddr, r.Type)
+	}
+	// check for input pins can be configured
+	pinsCount, configurable := configurablePins[bType]
+	if len(r.InputPins) > 0 && !configurable {
+		err = fmt.Errorf("Input pins can not be configured for type '%s'", r.Type)
+	}
+	for _, pin := range r.InputPins {
+		if configurable && pin >= pinsCount {
+			err = fmt.Errorf("The given input pin %d is out of range 0..%d", pin, pinsCount-1)
+		}
+	}
+	// check for board definition file is given for custom boards only
+	if bType == Custom && r.Definition == "" {
+		err = fmt.Errorf("The board definition file is missing for type '%s'", r.Type)
+	}
+	if bType != Custom && r.Definition != "" {
+		err = fmt.Errorf("A board definition file can not be used for type '%s'", r.Type)
+	}
+	// check for valid memory range, "MemoryTo" zero means the default range of the board type
+	if r.HasMemoryRange() {
+		if !memoryTypes[bType] {
+			err = fmt.Errorf("A memory range can not be used for type '%s'", r.Type)
+		}
+		if r.MemoryFrom > r.MemoryTo || r.MemoryTo >= memoryReservedStart {
+			err = fmt.Errorf("The given memory range %d..%d is not valid, allowed is 0..%d", r.MemoryFrom, r.MemoryTo,
+				memoryReservedStart-1)
+		}
+	} else if r.MemoryFrom != 0 {
+		err = fmt.Errorf("The given memory start %d needs an end 'MemoryTo' in range 1..%d", r.MemoryFrom,
+			memoryReservedStart-1)
+	}
+	// check for valid multiplexer TCA9548A (0x70..0x77) with channel 0..7, address 0 means no multiplexer
+	if r.HasMultiplexer() {
+		if !r.UsesBus() {
+			err = fmt.Errorf("A multiplexer can not be used for type '%s'", r.Type)
+		}
+		if r.MuxAddr < 0x70 || r.MuxAddr > 0x77 {
+			err = fmt.Errorf("The given multiplexer address 0x%02X is not valid", r.MuxAddr)
+		}
+		if r.MuxChannel > 7 {
+			err = fmt.Errorf("The given multiplexer channel %d is out of range 0..7", r.MuxChannel)
+		}
+	} else if r.MuxChannel != 0 {
+		err = fmt.Errorf("The given multiplexer channel %d needs a multiplexer address", r.MuxChannel)
 	}
 	return
 }
 
-func (r Ingredients) String() string {
-	return fmt.Sprintf("Name: %s, Type: %s, Chip address: %d", r.Name, r.Type, r.ChipDevAddr)
+// NeedsAdaptor states true when the board can only be created with a real adaptor
+func (r Ingredients) NeedsAdaptor() bool {
+	return TypeMap[r.Type] != Virtual8io
+}
+
+// UsesBus states true when the board is connected to the i2c bus of the adaptor
+func (r Ingredients) UsesBus() bool {
+	return r.NeedsAdaptor() && TypeMap[r.Type] != HostGPIO
+}
+
+// HasMemoryRange states true when the range of the memory pins is configured
+func (r Ingredients) HasMemoryRange() bool {
+	return r.MemoryTo != 0
+}
+
+// HasMultiplexer states true when the board is connected to a channel of a multiplexer
+func (r Ingredients) HasMultiplexer() bool {
+	return r.MuxAddr != 0
+}
+
+func (r Ingredients) String() (toString string) {
+	toString = fmt.Sprintf("Name: %s, Type: %s, Chip address: %d", r.Name, r.Type, r.ChipDevAddr)
+	if len(r.InputPins) > 0 {
+		toString = fmt.Sprintf("%s, Input pins: %v", toString, r.InputPins)
+	}
+	if r.Definition != "" {
+		toString = fmt.Sprintf("%s, Definition: %s", toString, r.Definition)
+	}
+	if r.HasMemoryRange() {
+		toString = fmt.Sprintf("%s, Memory range: %d..%d", toString, r.MemoryFrom, r.MemoryTo)
+	}
+	if r.HasMultiplexer() {
+		toString = fmt.Sprintf("%s, Multiplexer address: %d, Channel: %d", toString, r.MuxAddr, r.MuxChannel)
+	}
+	return
 }
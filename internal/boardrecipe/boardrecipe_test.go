@@ -19,6 +19,82 @@ func TestVerify(t *testing.T) {
 	var verifyTests = map[string]verifyTest{
 		"WrongType": {di: Ingredients{Type: "WrongType"}, wantErr: "type 'WrongType' is unknown"},
 		"NoError":   {di: Ingredients{Type: "Type2io"}},
+		"PCF8574WrongAddress": {
+			di:      Ingredients{Type: "PCF8574io", ChipDevAddr: 0x28},
+			wantErr: "address 0x28 is not valid",
+		},
+		"PCF8574":  {di: Ingredients{Type: "PCF8574i", ChipDevAddr: 0x27}},
+		"PCF8574A": {di: Ingredients{Type: "PCF8574o", ChipDevAddr: 0x38}},
+		"MCP23017WrongAddress": {
+			di:      Ingredients{Type: "MCP23017", ChipDevAddr: 0x38},
+			wantErr: "address 0x38 is not valid",
+		},
+		"MCP23017InputPinOutOfRange": {
+			di:      Ingredients{Type: "MCP23017", ChipDevAddr: 0x20, InputPins: []uint8{2, 16}},
+			wantErr: "input pin 16 is out of range",
+		},
+		"InputPinsNotConfigurable": {
+			di:      Ingredients{Type: "Type2io", InputPins: []uint8{2}},
+			wantErr: "can not be configured for type 'Type2io'",
+		},
+		"PCA9685WrongAddress": {
+			di:      Ingredients{Type: "PCA9685", ChipDevAddr: 0x3F},
+			wantErr: "address 0x3F is not valid",
+		},
+		"PCA9685AddressTooHigh": {
+			di:      Ingredients{Type: "PCA9685", ChipDevAddr: 0x80},
+			wantErr: "address 0x80 is not valid",
+		},
+		"PCA9685":  {di: Ingredients{Type: "PCA9685", ChipDevAddr: 0x40}},
+		"MCP23017": {di: Ingredients{Type: "MCP23017", ChipDevAddr: 0x20, InputPins: []uint8{0, 15}}},
+		"MuxWrongAddress": {
+			di:      Ingredients{Type: "Type2i", ChipDevAddr: 0x01, MuxAddr: 0x78},
+			wantErr: "multiplexer address 0x78 is not valid",
+		},
+		"MuxChannelOutOfRange": {
+			di:      Ingredients{Type: "Type2i", ChipDevAddr: 0x01, MuxAddr: 0x70, MuxChannel: 8},
+			wantErr: "channel 8 is out of range",
+		},
+		"MuxChannelWithoutAddress": {
+			di:      Ingredients{Type: "Type2i", ChipDevAddr: 0x01, MuxChannel: 3},
+			wantErr: "needs a multiplexer address",
+		},
+		"MuxForVirtual": {
+			di:      Ingredients{Type: "Virtual8io", MuxAddr: 0x70},
+			wantErr: "can not be used for type 'Virtual8io'",
+		},
+		"Mux": {di: Ingredients{Type: "Type2io", ChipDevAddr: 0x01, MuxAddr: 0x77, MuxChannel: 7}},
+		"CustomDefinitionMissing": {
+			di:      Ingredients{Type: "Custom", ChipDevAddr: 0x01},
+			wantErr: "definition file is missing for type 'Custom'",
+		},
+		"DefinitionNotUsable": {
+			di:      Ingredients{Type: "Type2io", Definition: "carrier.json"},
+			wantErr: "can not be used for type 'Type2io'",
+		},
+		"Custom":   {di: Ingredients{Type: "Custom", ChipDevAddr: 0x01, Definition: "carrier.json"}},
+		"HostGPIO": {di: Ingredients{Type: "HostGPIO", InputPins: []uint8{0, 16}}},
+		"HostGPIOInputPinOutOfRange": {
+			di:      Ingredients{Type: "HostGPIO", InputPins: []uint8{17}},
+			wantErr: "input pin 17 is out of range 0..16",
+		},
+		"MuxForHostGPIO": {
+			di:      Ingredients{Type: "HostGPIO", MuxAddr: 0x70},
+			wantErr: "can not be used for type 'HostGPIO'",
+		},
+		"MemoryRange": {di: Ingredients{Type: "Type2io", ChipDevAddr: 0x01, MemoryFrom: 0x10, MemoryTo: 0xBF}},
+		"MemoryRangeReserved": {
+			di:      Ingredients{Type: "Type2io", ChipDevAddr: 0x01, MemoryFrom: 0x10, MemoryTo: 0xC0},
+			wantErr: "memory range 16..192 is not valid, allowed is 0..191",
+		},
+		"MemoryRangeToMissing": {
+			di:      Ingredients{Type: "Type2i", ChipDevAddr: 0x01, MemoryFrom: 0x10},
+			wantErr: "memory start 16 needs an end 'MemoryTo' in range 1..191",
+		},
+		"MemoryRangeWithoutMemory": {
+			di:      Ingredients{Type: "PCF8574i", ChipDevAddr: 0x20, MemoryTo: 0x10},
+			wantErr: "memory range can not be used for type 'PCF8574i'",
+		},
 	}
 	for name, vt := range verifyTests {
 		t.Run(name, func(t *testing.T) {
@@ -38,6 +114,39 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestHasMultiplexer(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act & assert
+	assert.Equal(true, Ingredients{Type: "Type2io", MuxAddr: 0x70}.HasMultiplexer())
+	assert.Equal(false, Ingredients{Type: "Type2io"}.HasMultiplexer())
+}
+
+func TestHasMemoryRange(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act & assert
+	assert.Equal(true, Ingredients{Type: "Type2io", MemoryTo: 0x0F}.HasMemoryRange())
+	assert.Equal(false, Ingredients{Type: "Type2io"}.HasMemoryRange())
+}
+
+func TestNeedsAdaptor(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act & assert
+	assert.Equal(true, Ingredients{Type: "Type2io"}.NeedsAdaptor())
+	assert.Equal(false, Ingredients{Type: "Virtual8io"}.NeedsAdaptor())
+}
+
+func TestUsesBus(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act & assert
+	assert.Equal(true, Ingredients{Type: "Type2io"}.UsesBus())
+	assert.Equal(false, Ingredients{Type: "Virtual8io"}.UsesBus())
+	assert.Equal(false, Ingredients{Type: "HostGPIO"}.UsesBus())
+}
+
 func TestReadIngredients(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
@@ -55,3 +164,20 @@ func TestReadIngredients(t *testing.T) {
 	assert.Equal("Type2io", ing.Type)
 	assert.Equal(uint8(1), ing.ChipDevAddr)
 }
+
+func TestReadIngredientsCustom(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	oldSchema := Schema
+	Schema, _ = filepath.Abs("../../schemas/board.schema.json")
+	defer func() { Schema = oldSchema }()
+	recipe := recipesBase + "boardrecipes/board_custom.json"
+	// act
+	ing, err := ReadIngredients(recipe)
+	// assert
+	require.Nil(err)
+	assert.Equal("Custom", ing.Type)
+	assert.Equal("./test/data/boarddefinitions/carrier_2xPCA9501.json", ing.Definition)
+	assert.Equal(true, ing.NeedsAdaptor())
+}
@@ -0,0 +1,195 @@
+package boardsapi
+
+// The board health keeps the railroad running, when the i2c connection to a single board is disturbed
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : some functions from board
+//
+// A failed read or write is repeated immediately, because most bus errors are transient (e.g. a spike).
+// After some failed operations in sequence the board is marked offline. While offline, no transaction is done for
+// the board: writes are only recorded and reads get the last known value, so all other boards keep running.
+// At begin of a cycle an offline board is probed from time to time. When it is reachable again, all recorded
+// outputs are restored, because the chip may have lost its state (e.g. power on reset by a loose connector).
+// Only the transitions are reported: going offline by the returned error and getting online again by the log.
+//
+// Functions:
+// + repeat failed operations
+// + mark board offline after failures in sequence
+// + probe offline boards and restore its outputs
+// + report offline and online transitions
+//
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gen2thomas/gobrail/internal/board"
+)
+
+const (
+	// maxRetries is the count of repetitions of a failed operation
+	maxRetries = 2
+	// maxFailures is the count of failed operations in sequence, which causes the board to be offline
+	maxFailures = 3
+	// defaultProbeInterval is the minimal time between two probes of an offline board
+	defaultProbeInterval = 2 * time.Second
+)
+
+type boardHealth struct {
+	failures  int
+	offline   bool
+	lastProbe time.Time
+	inputs    map[uint8]uint8
+	outputs   map[uint8]uint8
+}
+
+func newBoardHealth() *boardHealth {
+	return &boardHealth{
+		inputs:  make(map[uint8]uint8),
+		outputs: make(map[uint8]uint8),
+	}
+}
+
+// OfflineBoards gets the names of all boards, which are not reachable at the moment
+func (bi *BoardsAPI) OfflineBoards() (boardIDs []string) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	for boardID, health := range bi.health {
+		if health.offline {
+			boardIDs = append(boardIDs, boardID)
+		}
+	}
+	return
+}
+
+func (bi *BoardsAPI) getHealth(boardID string) *boardHealth {
+	if bi.health == nil {
+		bi.health = make(map[string]*boardHealth)
+	}
+	health, ok := bi.health[boardID]
+	if !ok {
+		health = newBoardHealth()
+		bi.health[boardID] = health
+	}
+	return health
+}
+
+// readValue reads the board pin with repetition, while the board is offline the last known value is returned
+func (bi *BoardsAPI) readValue(boardID string, boardPinNr uint8) (value uint8, err error) {
+	boardToRead, ok := bi.boards[boardID]
+	if !ok {
+		return 0, fmt.Errorf("Board '%s' not there", boardID)
+	}
+	health := bi.getHealth(boardID)
+	if health.offline {
+		return health.inputs[boardPinNr], nil
+	}
+	err = withRetry(func() (err error) {
+		value, err = boardToRead.ReadValue(boardPinNr)
+		return
+	})
+	if _, known := health.inputs[boardPinNr]; err == nil || !known {
+		// also a failed pin is recorded, so it can be used for probing
+		health.inputs[boardPinNr] = value
+	}
+	return value, health.track(boardID, err)
+}
+
+// writeValue writes the board pin with repetition, while the board is offline the value is only recorded
+func (bi *BoardsAPI) writeValue(boardID string, boardPinNr uint8, value uint8) (err error) {
+	boardToWrite, ok := bi.boards[boardID]
+	if !ok {
+		return fmt.Errorf("Board '%s' not there", boardID)
+	}
+	health := bi.getHealth(boardID)
+	if health.offline {
+		health.outputs[boardPinNr] = value
+		return
+	}
+	err = withRetry(func() error { return boardToWrite.WriteValue(boardPinNr, value) })
+	if !errors.Is(err, board.ErrPinUsage) {
+		health.outputs[boardPinNr] = value
+	}
+	return health.track(boardID, err)
+}
+
+// flushOutputs writes all pending outputs, on failure all recorded outputs will be written again
+func (bi *BoardsAPI) flushOutputs(boardID string, boardToFlush Boarder) (err error) {
+	health := bi.getHealth(boardID)
+	if health.offline {
+		return
+	}
+	if err = boardToFlush.FlushOutputs(); err != nil {
+		// the pending changes are lost after a failed write
+		err = withRetry(func() error { return restoreOutputs(boardToFlush, health.outputs) })
+	}
+	return health.track(boardID, err)
+}
+
+// probe tries to reach the offline board again, on success all recorded outputs are restored
+func (bi *BoardsAPI) probe(boardID string, boardToProbe Boarder) {
+	health := bi.getHealth(boardID)
+	if !health.offline || time.Since(health.lastProbe) < bi.probeInterval {
+		return
+	}
+	health.lastProbe = time.Now()
+	for _, driver := range boardToProbe.GobotDevices() {
+		if err := driver.Start(); err != nil {
+			return
+		}
+	}
+	// the output latches may have lost their state, so the shadow must not be used to skip writes
+	boardToProbe.ExpireOutputs()
+	for boardPinNr := range health.inputs {
+		if _, err := boardToProbe.ReadValue(boardPinNr); err != nil {
+			return
+		}
+	}
+	if err := restoreOutputs(boardToProbe, health.outputs); err != nil {
+		return
+	}
+	health.offline = false
+	health.failures = 0
+	log.Printf("Board '%s' is online again\n", boardID)
+}
+
+// track counts the failed operations in sequence and marks the board offline, when the limit is reached
+func (h *boardHealth) track(boardID string, err error) error {
+	if err == nil {
+		h.failures = 0
+		return nil
+	}
+	if errors.Is(err, board.ErrPinUsage) {
+		return err
+	}
+	h.failures++
+	if h.failures < maxFailures {
+		return err
+	}
+	h.offline = true
+	h.lastProbe = time.Now()
+	return fmt.Errorf("Board '%s' is offline after %d failures in sequence, %w", boardID, h.failures, err)
+}
+
+func restoreOutputs(boardToRestore Boarder, outputs map[uint8]uint8) (err error) {
+	for boardPinNr, value := range outputs {
+		if err = boardToRestore.WriteValue(boardPinNr, value); err != nil {
+			return
+		}
+	}
+	return boardToRestore.FlushOutputs()
+}
+
+func withRetry(operation func() (err error)) (err error) {
+	for try := 0; try <= maxRetries; try++ {
+		if err = operation(); err == nil || errors.Is(err, board.ErrPinUsage) {
+			return
+		}
+	}
+	return
+}
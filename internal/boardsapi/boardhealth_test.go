@@ -0,0 +1,207 @@
+package boardsapi
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/board"
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+	"github.com/gen2thomas/gobrail/internal/boardrecipe"
+	"github.com/gen2thomas/gobrail/internal/simadaptor"
+)
+
+type flakyBoardMock struct {
+	boardsMock
+	broken   bool
+	failNext int
+	usageErr bool
+	calls    int
+	outputs  map[uint8]uint8
+}
+
+func newAPIWithFlakyBoard() (*BoardsAPI, *flakyBoardMock) {
+	flaky := &flakyBoardMock{boardsMock: boardsMock{name: "TestBoard", binPins: 4}, outputs: make(map[uint8]uint8)}
+	api := NewBoardsAPI(nil)
+	api.boards["TestBoard"] = flaky
+	api.usedPins["TestBoard"] = make(boardpin.PinNumbers)
+	api.health["TestBoard"] = newBoardHealth()
+	return api, flaky
+}
+
+func TestReadValueRetriesTransientError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api, flaky := newAPIWithFlakyBoard()
+	flaky.failNext = maxRetries
+	input, _ := api.GetInputPin("TestBoard", 1)
+	// act
+	value, err := input.ReadValue()
+	// assert
+	require.Nil(err)
+	assert.Equal(uint8(1), value)
+	assert.Equal(maxRetries+1, flaky.calls)
+	assert.Equal(0, api.health["TestBoard"].failures)
+}
+
+func TestBoardIsOfflineAfterFailures(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api, flaky := newAPIWithFlakyBoard()
+	input, _ := api.GetInputPin("TestBoard", 1)
+	_, _ = input.ReadValue()
+	flaky.broken = true
+	// act
+	var errs []error
+	for i := 0; i < maxFailures; i++ {
+		_, err := input.ReadValue()
+		errs = append(errs, err)
+	}
+	callsWhenOffline := flaky.calls
+	value, errOffline := input.ReadValue()
+	// assert
+	require.NotNil(errs[0])
+	assert.NotContains(errs[0].Error(), "offline")
+	require.NotNil(errs[maxFailures-1])
+	assert.Contains(errs[maxFailures-1].Error(), "Board 'TestBoard' is offline")
+	assert.Equal([]string{"TestBoard"}, api.OfflineBoards())
+	assert.Nil(errOffline)
+	assert.Equal(uint8(1), value)
+	assert.Equal(callsWhenOffline, flaky.calls)
+}
+
+func TestOfflineBoardIsRestoredOnProbe(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api, flaky := newAPIWithFlakyBoard()
+	api.probeInterval = 0
+	output1, _ := api.GetOutputPin("TestBoard", 1)
+	output2, _ := api.GetOutputPin("TestBoard", 2)
+	require.Nil(output1.WriteValue(1))
+	flaky.broken = true
+	for i := 0; i < maxFailures; i++ {
+		output1.WriteValue(0)
+	}
+	require.Equal([]string{"TestBoard"}, api.OfflineBoards())
+	errOffline := output2.WriteValue(1)
+	// act
+	api.BeginCycle()
+	stillOffline := api.OfflineBoards()
+	flaky.broken = false
+	api.BeginCycle()
+	// assert
+	assert.Nil(errOffline)
+	assert.Equal([]string{"TestBoard"}, stillOffline)
+	assert.Empty(api.OfflineBoards())
+	assert.Equal(map[uint8]uint8{1: 0, 2: 1}, flaky.outputs)
+}
+
+func TestOfflineBoardIsNotProbedBeforeInterval(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	api, flaky := newAPIWithFlakyBoard()
+	api.probeInterval = time.Hour
+	api.health["TestBoard"].offline = true
+	api.health["TestBoard"].lastProbe = time.Now()
+	// act
+	api.BeginCycle()
+	// assert
+	assert.Equal(0, flaky.calls)
+	assert.Equal([]string{"TestBoard"}, api.OfflineBoards())
+}
+
+func TestPinUsageErrorIsNotRetried(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api, flaky := newAPIWithFlakyBoard()
+	flaky.usageErr = true
+	output, _ := api.GetOutputPin("TestBoard", 1)
+	// act
+	for i := 0; i < maxFailures; i++ {
+		output.WriteValue(1)
+	}
+	err := output.WriteValue(1)
+	// assert
+	require.NotNil(err)
+	assert.ErrorIs(err, board.ErrPinUsage)
+	assert.Equal(maxFailures+1, flaky.calls)
+	assert.Empty(api.OfflineBoards())
+	assert.Empty(api.health["TestBoard"].outputs)
+}
+
+func TestEndCycleRestoresOutputsWhenFlushFails(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api, flaky := newAPIWithFlakyBoard()
+	output, _ := api.GetOutputPin("TestBoard", 3)
+	require.Nil(output.WriteValue(1))
+	delete(flaky.outputs, 3)
+	flaky.failNext = 1
+	// act
+	err := api.EndCycle()
+	// assert
+	require.Nil(err)
+	assert.Equal(map[uint8]uint8{3: 1}, flaky.outputs)
+}
+
+func TestProbeRestoresOutputsAfterPowerOnReset(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	sim := simadaptor.NewAdaptor()
+	api := NewBoardsAPI(sim)
+	api.probeInterval = 0
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x01, Type: "Type2o"}))
+	for _, device := range api.GobotDevices() {
+		require.Nil(device.Start())
+	}
+	output, _ := api.GetOutputPin("TestBoard", 0)
+	require.Nil(output.WriteValue(0))
+	require.Equal(uint8(0xFE), sim.Port(0x01))
+	api.health["TestBoard"].offline = true
+	sim.PowerOnReset(0x01)
+	// act
+	api.BeginCycle()
+	// assert
+	assert.Empty(api.OfflineBoards())
+	assert.Equal(uint8(0xFE), sim.Port(0x01))
+}
+
+func (b *flakyBoardMock) operation() error {
+	b.calls++
+	if b.usageErr {
+		return fmt.Errorf("usage error, %w", board.ErrPinUsage)
+	}
+	if b.broken {
+		return fmt.Errorf("bus error")
+	}
+	if b.failNext > 0 {
+		b.failNext--
+		return fmt.Errorf("transient error")
+	}
+	return nil
+}
+
+func (b *flakyBoardMock) ReadValue(boardPinNr uint8) (uint8, error) {
+	return 1, b.operation()
+}
+
+func (b *flakyBoardMock) WriteValue(boardPinNr uint8, value uint8) (err error) {
+	if err = b.operation(); err != nil {
+		return
+	}
+	b.outputs[boardPinNr] = value
+	return
+}
+
+func (b *flakyBoardMock) FlushOutputs() (err error) {
+	return b.operation()
+}
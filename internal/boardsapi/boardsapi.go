@@ -12,27 +12,42 @@ package boardsapi
 //
 // Functions:
 // + get input and output pins and mark used
+// + get analog output pins (e.g. PWM) and mark used
+// + get memory pins for read and write and mark used
+// + release pins (remove used mark)
 // + get all pin numbers of a board
 // + get used pin numbers of a board
 // + get available pin numbers of a board
+// + begin a new cycle to read inputs only once per cycle
+// + end a cycle to write all pending outputs
+// + discover not used boards and generate recipes
+// + write and verify identity of boards
+// + boards described by a board definition file
+// + GPIO header of the host as board
+// + configurable range of the memory pins
+// + boards behind a channel of a TCA9548A i2c multiplexer
+// + retry failed operations, mark boards offline and restore them when reachable again
+// + safe for concurrent use, all access to boards is serialized
 //
 // TODO:
-// - release pins (remove used mark)
 // - split generate recipes or read recipes from config
 // - store configuration in host EEPROM or file (maybe not necessary when recipes is working)
-// - detect an device and add to boards (or generate recipe automatically)
-// - support for cascades
 //
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/drivers/i2c"
 
 	"github.com/gen2thomas/gobrail/internal/board"
+	"github.com/gen2thomas/gobrail/internal/boarddefinition"
 	"github.com/gen2thomas/gobrail/internal/boardpin"
 	"github.com/gen2thomas/gobrail/internal/boardrecipe"
+	"github.com/gen2thomas/gobrail/internal/errwrap"
+	"github.com/gen2thomas/gobrail/internal/i2cmux"
 )
 
 // ConfigurationOperations is an interface for interact with configuration part
@@ -44,9 +59,20 @@ type ConfigurationOperations interface {
 type Boarder interface {
 	ConfigurationOperations
 	GobotDevices() []gobot.Device
+	ChipAddresses() []uint8
 	GetPinNumbers() boardpin.PinNumbers
+	GetPinNumbersOfType(pinTypes ...boardpin.PinType) boardpin.PinNumbers
 	ReadValue(boardPinNr uint8) (uint8, error)
 	WriteValue(boardPinNr uint8, value uint8) (err error)
+	ExpireInputs()
+	ExpireOutputs()
+	SetCoalescedWrites(coalesce bool)
+	SetServoMode(boardPinNr uint8, servo bool) (err error)
+	FlushOutputs() (err error)
+	HasMemory() bool
+	SetMemoryRange(from uint8, to uint8) (err error)
+	ReadIdentity() (identity board.Identity, ok bool, err error)
+	WriteIdentity(identity board.Identity) (err error)
 }
 
 // BoardsMap is the list of already created boards
@@ -54,49 +80,224 @@ type BoardsMap map[string]Boarder
 
 // BoardsAPI is the main object for API access
 type BoardsAPI struct {
-	usedPins map[string]boardpin.PinNumbers
-	boards   BoardsMap
-	adaptor  i2c.Connector
+	usedPins        map[string]boardpin.PinNumbers
+	boards          BoardsMap
+	recipes         map[string]boardrecipe.Ingredients
+	adaptor         i2c.Connector
+	muxes           map[uint8]*i2cmux.Multiplexer
+	health          map[string]*boardHealth
+	probeInterval   time.Duration
+	coalescedWrites bool
+	mutex           sync.Mutex
 }
 
 // NewBoardsAPI creates a new API access
 func NewBoardsAPI(adaptor i2c.Connector) *BoardsAPI {
 	return &BoardsAPI{
-		usedPins: make(map[string]boardpin.PinNumbers),
-		boards:   make(BoardsMap),
-		adaptor:  adaptor,
+		usedPins:      make(map[string]boardpin.PinNumbers),
+		boards:        make(BoardsMap),
+		recipes:       make(map[string]boardrecipe.Ingredients),
+		adaptor:       adaptor,
+		muxes:         make(map[uint8]*i2cmux.Multiplexer),
+		health:        make(map[string]*boardHealth),
+		probeInterval: defaultProbeInterval,
 	}
 }
 
 // AddBoard creates a new board using recipe and add to list
 func (bi *BoardsAPI) AddBoard(boardRecipe boardrecipe.Ingredients) (err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	if _, ok := bi.boards[boardRecipe.Name]; ok {
 		return fmt.Errorf("Board already there '%s'", boardRecipe.Name)
 	}
+	adaptor := bi.adaptor
+	if boardRecipe.HasMultiplexer() {
+		if adaptor, err = bi.getMuxChannel(boardRecipe.MuxAddr, boardRecipe.MuxChannel); err != nil {
+			return
+		}
+	}
 	var newBoard Boarder
 	switch boardrecipe.TypeMap[boardRecipe.Type] {
 	case boardrecipe.Type2i:
-		newBoard = board.NewBoardType2i(bi.adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
+		newBoard = board.NewBoardType2i(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
 	case boardrecipe.Type2o:
-		newBoard = board.NewBoardType2o(bi.adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
+		newBoard = board.NewBoardType2o(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
 	case boardrecipe.Type2io:
-		newBoard = board.NewBoardType2io(bi.adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
+		newBoard = board.NewBoardType2io(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
+	case boardrecipe.PCF8574i:
+		newBoard = board.NewBoardPCF8574i(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
+	case boardrecipe.PCF8574o:
+		newBoard = board.NewBoardPCF8574o(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
+	case boardrecipe.PCF8574io:
+		newBoard = board.NewBoardPCF8574io(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
+	case boardrecipe.MCP23017:
+		newBoard = board.NewBoardMCP23017(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name, boardRecipe.InputPins)
+	case boardrecipe.PCA9685:
+		newBoard = board.NewBoardPCA9685(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name)
+	case boardrecipe.Virtual8io:
+		newBoard = board.NewBoardVirtual8io(boardRecipe.Name)
+	case boardrecipe.Custom:
+		var definition boarddefinition.Definition
+		if definition, err = boarddefinition.ReadDefinition(boardRecipe.Definition); err != nil {
+			return
+		}
+		if newBoard, err = board.NewBoardFromDefinition(adaptor, boardRecipe.ChipDevAddr, boardRecipe.Name,
+			definition); err != nil {
+			return
+		}
+	case boardrecipe.HostGPIO:
+		for _, recipe := range bi.recipes {
+			if recipe.Type == boardRecipe.Type {
+				return fmt.Errorf("The header of host is already used by board '%s'", recipe.Name)
+			}
+		}
+		if newBoard, err = board.NewBoardHostGPIO(adaptor, boardRecipe.Name, boardRecipe.InputPins); err != nil {
+			return
+		}
 	default:
 		return fmt.Errorf("Unknown type '%s'", boardRecipe.Type)
 	}
+	if boardRecipe.HasMemoryRange() {
+		if err = newBoard.SetMemoryRange(boardRecipe.MemoryFrom, boardRecipe.MemoryTo); err != nil {
+			return
+		}
+	}
+	newBoard.SetCoalescedWrites(bi.coalescedWrites)
 	bi.boards[boardRecipe.Name] = newBoard
+	bi.recipes[boardRecipe.Name] = boardRecipe
 	bi.usedPins[boardRecipe.Name] = make(boardpin.PinNumbers)
+	bi.health[boardRecipe.Name] = newBoardHealth()
 	return
 }
 
+// getMuxChannel gets the connector for the channel of the multiplexer, all boards share the same multiplexer
+func (bi *BoardsAPI) getMuxChannel(muxAddr uint8, channel uint8) (connector i2c.Connector, err error) {
+	if bi.adaptor == nil {
+		return nil, fmt.Errorf("Multiplexer at address 0x%02X needs an adaptor", muxAddr)
+	}
+	mux, ok := bi.muxes[muxAddr]
+	if !ok {
+		mux = i2cmux.NewMultiplexer(bi.adaptor, muxAddr)
+		bi.muxes[muxAddr] = mux
+	}
+	return mux.Channel(channel)
+}
+
 // RemoveBoard remove board from list
 func (bi *BoardsAPI) RemoveBoard(boardID string) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	delete(bi.boards, boardID)
+	delete(bi.recipes, boardID)
 	delete(bi.usedPins, boardID)
+	delete(bi.health, boardID)
+}
+
+// DiscoverBoards searches the bus for boards, which are not already added and creates a recipe for each
+// the type can not be detected, so "Type2i" is used to prevent driving outputs at unknown hardware
+func (bi *BoardsAPI) DiscoverBoards() (recipes []boardrecipe.Ingredients, err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	if bi.adaptor == nil {
+		return nil, fmt.Errorf("Discovery of boards needs an adaptor")
+	}
+	var usedAddresses []uint8
+	// boards behind a multiplexer are not reachable while the channel is not selected
+	// all chips of a board are skipped, e.g. the chips at the address offsets of a custom board
+	for boardID, recipe := range bi.recipes {
+		if recipe.UsesBus() && !recipe.HasMultiplexer() {
+			usedAddresses = append(usedAddresses, bi.boards[boardID].ChipAddresses()...)
+		}
+	}
+	for _, address := range board.SearchPCA9501(bi.adaptor, usedAddresses) {
+		recipe := boardrecipe.Ingredients{
+			Name:        fmt.Sprintf("Board_0x%02X", address),
+			Type:        "Type2i",
+			ChipDevAddr: address,
+		}
+		if identity, ok := bi.readIdentityAt(address); ok {
+			recipe.Name = identity.Name
+			recipe.Type = identity.Type
+		}
+		recipes = append(recipes, recipe)
+	}
+	return
+}
+
+// WriteBoardIdentity writes name and type of the board recipe to the EEPROM of the board
+func (bi *BoardsAPI) WriteBoardIdentity(boardID string) (err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	boardToWrite, ok := bi.boards[boardID]
+	if !ok {
+		return fmt.Errorf("Board '%s' not there", boardID)
+	}
+	recipe := bi.recipes[boardID]
+	return boardToWrite.WriteIdentity(board.Identity{Name: recipe.Name, Type: recipe.Type})
+}
+
+// RecipesWithMemory gets the recipes of all boards, which provides an EEPROM for storing the identity
+func (bi *BoardsAPI) RecipesWithMemory() (recipes []boardrecipe.Ingredients) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	for boardID, board := range bi.boards {
+		if board.HasMemory() {
+			recipes = append(recipes, bi.recipes[boardID])
+		}
+	}
+	return
+}
+
+// VerifyBoardIdentities reads the identity of all boards with EEPROM and compares it with the board recipe
+// boards without stored identity are returned, an error is returned for each different identity
+func (bi *BoardsAPI) VerifyBoardIdentities() (unknownBoards []string, err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	for boardID, board := range bi.boards {
+		if !board.HasMemory() {
+			continue
+		}
+		identity, ok, readErr := board.ReadIdentity()
+		if readErr != nil {
+			err = errwrap.Wrap(err, fmt.Errorf("Identity of board '%s' can not be read: %s", boardID, readErr.Error()))
+			continue
+		}
+		if !ok {
+			unknownBoards = append(unknownBoards, boardID)
+			continue
+		}
+		recipe := bi.recipes[boardID]
+		if identity.Name != recipe.Name || identity.Type != recipe.Type {
+			err = errwrap.Wrap(err, fmt.Errorf("Board '%s' (%s) at address 0x%02X has identity of board '%s' (%s)",
+				recipe.Name, recipe.Type, recipe.ChipDevAddr, identity.Name, identity.Type))
+		}
+	}
+	return
+}
+
+func (bi *BoardsAPI) readIdentityAt(address uint8) (identity board.Identity, ok bool) {
+	probe := board.NewBoardType2i(bi.adaptor, address, "")
+	for _, driver := range probe.GobotDevices() {
+		if err := driver.Start(); err != nil {
+			return
+		}
+	}
+	identity, ok, _ = probe.ReadIdentity()
+	return
 }
 
 // GetFreePins gets all not used board pins
 func (bi *BoardsAPI) GetFreePins(boardID string) (freePins boardpin.PinNumbers) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	var board Boarder
 	var ok bool
 	if board, ok = bi.boards[boardID]; !ok {
@@ -115,6 +316,13 @@ func (bi *BoardsAPI) GetFreePins(boardID string) (freePins boardpin.PinNumbers)
 
 // GetUsedPins gets all not used board pins
 func (bi *BoardsAPI) GetUsedPins(boardID string) (usedPins boardpin.PinNumbers) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	return bi.getUsedPins(boardID)
+}
+
+func (bi *BoardsAPI) getUsedPins(boardID string) (usedPins boardpin.PinNumbers) {
 	var ok bool
 	if _, ok = bi.boards[boardID]; !ok {
 		return
@@ -129,6 +337,9 @@ func (bi *BoardsAPI) GetUsedPins(boardID string) (usedPins boardpin.PinNumbers)
 
 // GetInputPin gets an board pin to use for read values
 func (bi *BoardsAPI) GetInputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Input, err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	// already mapped
 	if _, ok := bi.usedPins[boardID][boardPinNr]; ok {
 		return nil, fmt.Errorf("Board Pin '%d' at '%s' already used", boardPinNr, boardID)
@@ -138,7 +349,10 @@ func (bi *BoardsAPI) GetInputPin(boardID string, boardPinNr uint8) (boardPin *bo
 		BoardID:    boardID,
 		BoardPinNr: boardPinNr,
 		ReadValue: func() (value uint8, err error) {
-			return bi.boards[boardID].ReadValue(boardPinNr)
+			bi.mutex.Lock()
+			defer bi.mutex.Unlock()
+
+			return bi.readValue(boardID, boardPinNr)
 		},
 	}
 	if bi.usedPins[boardID] != nil {
@@ -152,6 +366,47 @@ func (bi *BoardsAPI) GetInputPin(boardID string, boardPinNr uint8) (boardPin *bo
 
 // GetOutputPin gets an board pin to use for write values
 func (bi *BoardsAPI) GetOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	return bi.newOutputPin(boardID, boardPinNr)
+}
+
+// GetAnalogOutputPin gets an analog board pin (e.g. PWM) to use for write values 0..255
+func (bi *BoardsAPI) GetAnalogOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	board, ok := bi.boards[boardID]
+	if !ok {
+		return nil, fmt.Errorf("Board '%s' not found", boardID)
+	}
+	if _, ok := board.GetPinNumbersOfType(boardpin.Analog, boardpin.AnalogW)[boardPinNr]; !ok {
+		return nil, fmt.Errorf("Board Pin '%d' at '%s' is not an analog output", boardPinNr, boardID)
+	}
+	return bi.newOutputPin(boardID, boardPinNr)
+}
+
+// GetServoOutputPin gets an analog board pin (e.g. PWM) to use for write positions 0..255 of a servo
+func (bi *BoardsAPI) GetServoOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	board, ok := bi.boards[boardID]
+	if !ok {
+		return nil, fmt.Errorf("Board '%s' not found", boardID)
+	}
+	if _, ok := bi.usedPins[boardID][boardPinNr]; ok {
+		return nil, fmt.Errorf("Board Pin '%d' at '%s' already used", boardPinNr, boardID)
+	}
+	if err = board.SetServoMode(boardPinNr, true); err != nil {
+		return nil, fmt.Errorf("Board Pin '%d' at '%s' is not a servo output, %w", boardPinNr, boardID, err)
+	}
+	return bi.newOutputPin(boardID, boardPinNr)
+}
+
+// newOutputPin creates the output pin and marks it used, the caller must hold the lock
+func (bi *BoardsAPI) newOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error) {
 	// already mapped
 	if _, ok := bi.usedPins[boardID][boardPinNr]; ok {
 		return nil, fmt.Errorf("Board Pin '%d' at '%s' already used", boardPinNr, boardID)
@@ -161,7 +416,10 @@ func (bi *BoardsAPI) GetOutputPin(boardID string, boardPinNr uint8) (boardPin *b
 		BoardID:    boardID,
 		BoardPinNr: boardPinNr,
 		WriteValue: func(value uint8) (err error) {
-			return bi.boards[boardID].WriteValue(boardPinNr, value)
+			bi.mutex.Lock()
+			defer bi.mutex.Unlock()
+
+			return bi.writeValue(boardID, boardPinNr, value)
 		},
 	}
 	if bi.usedPins[boardID] != nil {
@@ -173,8 +431,98 @@ func (bi *BoardsAPI) GetOutputPin(boardID string, boardPinNr uint8) (boardPin *b
 	return
 }
 
+// GetStoragePin gets a memory board pin to use for read and write values
+func (bi *BoardsAPI) GetStoragePin(boardID string, boardPinNr uint8) (boardPin *boardpin.Storage, err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	// already mapped
+	if _, ok := bi.usedPins[boardID][boardPinNr]; ok {
+		return nil, fmt.Errorf("Board Pin '%d' at '%s' already used", boardPinNr, boardID)
+	}
+	board, ok := bi.boards[boardID]
+	if !ok {
+		return nil, fmt.Errorf("Board '%s' not found", boardID)
+	}
+	if _, ok := board.GetPinNumbersOfType(boardpin.Memory)[boardPinNr]; !ok {
+		return nil, fmt.Errorf("Board Pin '%d' at '%s' is not a readable and writable memory", boardPinNr, boardID)
+	}
+	// create pin
+	boardPin = &boardpin.Storage{
+		BoardID:    boardID,
+		BoardPinNr: boardPinNr,
+		ReadValue: func() (value uint8, err error) {
+			bi.mutex.Lock()
+			defer bi.mutex.Unlock()
+
+			return bi.readValue(boardID, boardPinNr)
+		},
+		WriteValue: func(value uint8) (err error) {
+			bi.mutex.Lock()
+			defer bi.mutex.Unlock()
+
+			return bi.writeValue(boardID, boardPinNr, value)
+		},
+	}
+	bi.usedPins[boardID][boardPinNr] = struct{}{}
+	return
+}
+
+// ReleasePin removes the used mark of the board pin, so the pin can be used again
+func (bi *BoardsAPI) ReleasePin(boardID string, boardPinNr uint8) (err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	if _, ok := bi.boards[boardID]; !ok {
+		return fmt.Errorf("Board '%s' not found", boardID)
+	}
+	if _, ok := bi.usedPins[boardID][boardPinNr]; !ok {
+		return fmt.Errorf("Board Pin '%d' at '%s' is not used", boardPinNr, boardID)
+	}
+	delete(bi.usedPins[boardID], boardPinNr)
+	return bi.boards[boardID].SetServoMode(boardPinNr, false)
+}
+
+// BeginCycle prepares all boards for the next cycle, the inputs of all chips will be read again on next request
+// offline boards are probed from time to time
+func (bi *BoardsAPI) BeginCycle() {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	for boardID, board := range bi.boards {
+		board.ExpireInputs()
+		bi.probe(boardID, board)
+	}
+}
+
+// EndCycle finishes the cycle for all boards, all pending output changes will be written
+func (bi *BoardsAPI) EndCycle() (err error) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	for boardID, board := range bi.boards {
+		err = errwrap.Wrap(err, bi.flushOutputs(boardID, board))
+	}
+	return
+}
+
+// SetCoalescedWrites activates or deactivates coalescing of output writes for all boards, also for boards added later
+// when active, all output changes of a chip are written once at end of cycle, so pulses within a cycle will be lost
+func (bi *BoardsAPI) SetCoalescedWrites(coalesce bool) {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
+	bi.coalescedWrites = coalesce
+	for _, board := range bi.boards {
+		board.SetCoalescedWrites(coalesce)
+	}
+}
+
 // GobotDevices gets all gobot devices of all boards
 func (bi *BoardsAPI) GobotDevices() []gobot.Device {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	var allDevices gobot.Devices
 	for _, board := range bi.boards {
 		allDevices = append(allDevices, board.GobotDevices()...)
@@ -184,25 +532,37 @@ func (bi *BoardsAPI) GobotDevices() []gobot.Device {
 
 // ShowAvailableBoards list all created boards
 func (bi *BoardsAPI) ShowAvailableBoards() {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	fmt.Println(bi.boards)
 }
 
 // ShowAllUsedInputs list all used inputs of all boards
 func (bi *BoardsAPI) ShowAllUsedInputs() {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	fmt.Printf("------ Used Pins ------\n")
 	for id := range bi.boards {
-		fmt.Printf("Board '%s': %s\n", id, bi.GetUsedPins(id))
+		fmt.Printf("Board '%s': %s\n", id, bi.getUsedPins(id))
 	}
 }
 
 // ShowAllConfigs prints all information of all boards
 func (bi *BoardsAPI) ShowAllConfigs() {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	for id := range bi.boards {
 		bi.boards[id].ShowBoardConfig()
 	}
 }
 
 func (bi *BoardsAPI) String() string {
+	bi.mutex.Lock()
+	defer bi.mutex.Unlock()
+
 	return fmt.Sprintf("%s\n", bi.boards)
 }
 
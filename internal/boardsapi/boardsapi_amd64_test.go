@@ -0,0 +1,39 @@
+package boardsapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardrecipe"
+	"github.com/gen2thomas/gobrail/internal/simadaptor"
+)
+
+func TestAddBoardHostGPIOTwiceGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(simadaptor.NewAdaptor())
+	// act
+	err1 := api.AddBoard(boardrecipe.Ingredients{Name: "Host1", Type: "HostGPIO", InputPins: []uint8{0}})
+	err2 := api.AddBoard(boardrecipe.Ingredients{Name: "Host2", Type: "HostGPIO"})
+	// assert
+	require.Nil(err1)
+	require.NotNil(err2)
+	assert.Contains(err2.Error(), "header of host is already used by board 'Host1'")
+	assert.Equal(17, len(api.GetFreePins("Host1")))
+}
+
+func TestDiscoverBoardsIgnoresHostGPIO(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(simadaptor.NewAdaptor())
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "Host", Type: "HostGPIO"}))
+	// act
+	recipes, err := api.DiscoverBoards()
+	// assert
+	require.Nil(err)
+	assert.Equal(uint8(0x00), recipes[0].ChipDevAddr)
+}
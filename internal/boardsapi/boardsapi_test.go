@@ -2,6 +2,8 @@ package boardsapi
 
 import (
 	"fmt"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,8 +12,11 @@ import (
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/drivers/i2c"
 
+	"github.com/gen2thomas/gobrail/internal/board"
+	"github.com/gen2thomas/gobrail/internal/boarddefinition"
 	"github.com/gen2thomas/gobrail/internal/boardpin"
 	"github.com/gen2thomas/gobrail/internal/boardrecipe"
+	"github.com/gen2thomas/gobrail/internal/simadaptor"
 )
 
 type adaptorMock struct {
@@ -23,6 +28,8 @@ type boardsMock struct {
 	binPins uint8
 	anaPins uint8
 	memPins uint8
+	expired *bool
+	flushed *bool
 }
 
 type addBoardTest struct {
@@ -45,6 +52,12 @@ func TestBoardsAPIAddBoard(t *testing.T) {
 		"Type2i":       {bi: boardrecipe.Ingredients{Name: "TestRecipeType2i", ChipDevAddr: 0x01, Type: "Type2i"}},
 		"Type2o":       {bi: boardrecipe.Ingredients{Name: "TestRecipeType2o", ChipDevAddr: 0x02, Type: "Type2o"}},
 		"Type2io":      {bi: boardrecipe.Ingredients{Name: "TestRecipeType2io", ChipDevAddr: 0x03, Type: "Type2io"}},
+		"Virtual8io":   {bi: boardrecipe.Ingredients{Name: "TestRecipeVirtual8io", Type: "Virtual8io"}},
+		"PCF8574i":     {bi: boardrecipe.Ingredients{Name: "TestRecipePCF8574i", ChipDevAddr: 0x20, Type: "PCF8574i"}},
+		"PCF8574o":     {bi: boardrecipe.Ingredients{Name: "TestRecipePCF8574o", ChipDevAddr: 0x21, Type: "PCF8574o"}},
+		"PCF8574io":    {bi: boardrecipe.Ingredients{Name: "TestRecipePCF8574io", ChipDevAddr: 0x38, Type: "PCF8574io"}},
+		"PCA9685":      {bi: boardrecipe.Ingredients{Name: "TestRecipePCA9685", ChipDevAddr: 0x40, Type: "PCA9685"}},
+		"MCP23017":     {bi: boardrecipe.Ingredients{Name: "TestRecipeMCP23017", ChipDevAddr: 0x22, Type: "MCP23017", InputPins: []uint8{8}}},
 		"NotKnownType": {bi: boardrecipe.Ingredients{Name: "TestNotKnownType", ChipDevAddr: 0x03, Type: "NotKnownType"}, wantErr: true},
 	}
 	for name, at := range addBoardTests {
@@ -172,6 +185,354 @@ func TestGetUsedPins(t *testing.T) {
 	assert.Equal(6, len(usedPins))
 }
 
+func TestBeginCycle(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	var expired1, expired2 bool
+	api := &BoardsAPI{boards: make(BoardsMap)}
+	api.boards["TestBoard1"] = &boardsMock{name: "TestBoard1", expired: &expired1}
+	api.boards["TestBoard2"] = &boardsMock{name: "TestBoard2", expired: &expired2}
+	// act
+	api.BeginCycle()
+	// assert
+	assert.True(expired1)
+	assert.True(expired2)
+}
+
+func TestEndCycle(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	var flushed1, flushed2 bool
+	api := &BoardsAPI{boards: make(BoardsMap)}
+	api.boards["TestBoard1"] = &boardsMock{name: "TestBoard1", flushed: &flushed1}
+	api.boards["TestBoard2"] = &boardsMock{name: "TestBoard2", flushed: &flushed2}
+	// act
+	err := api.EndCycle()
+	// assert
+	assert.Nil(err)
+	assert.True(flushed1)
+	assert.True(flushed2)
+}
+
+func TestSetCoalescedWritesIsUsedForNewBoards(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	api := NewBoardsAPI(new(adaptorMock))
+	// act
+	api.SetCoalescedWrites(true)
+	err := api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x04, Type: "Virtual8io"})
+	// assert
+	assert.Nil(err)
+	assert.True(api.coalescedWrites)
+}
+
+func TestDiscoverBoardsWithoutAdaptorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	api := NewBoardsAPI(nil)
+	// act
+	recipes, err := api.DiscoverBoards()
+	// assert
+	assert.NotNil(err)
+	assert.Nil(recipes)
+}
+
+func TestDiscoverBoardsSkipsUsedAddresses(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(simadaptor.NewAdaptor())
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x04, Type: "Type2io"}))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestVirtual", Type: "Virtual8io"}))
+	// act
+	recipes, err := api.DiscoverBoards()
+	// assert
+	require.Nil(err)
+	// the simulation responds at each address
+	require.Equal(0x3F, len(recipes))
+	assert.Equal(uint8(0x00), recipes[0].ChipDevAddr)
+	assert.Equal(uint8(0x05), recipes[4].ChipDevAddr)
+	assert.Equal("Board_0x05", recipes[4].Name)
+	assert.Equal("Type2i", recipes[4].Type)
+}
+
+func TestAddBoardWithMultiplexer(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	sim := simadaptor.NewAdaptor()
+	api := NewBoardsAPI(sim)
+	// act
+	err1 := api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard1", ChipDevAddr: 0x01, Type: "Type2o", MuxAddr: 0x70})
+	err2 := api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard2", ChipDevAddr: 0x01, Type: "Type2o", MuxAddr: 0x70, MuxChannel: 1})
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	assert.Equal(2, len(api.boards))
+	assert.Equal(1, len(api.muxes))
+	devices := api.GobotDevices()
+	require.Equal(2, len(devices))
+	assert.Contains(devices[0].Connection().Name(), "TCA9548A_0x70_Channel")
+	require.Nil(devices[0].Start())
+	output, _ := api.GetOutputPin("TestBoard1", 0)
+	assert.Nil(output.WriteValue(0))
+	assert.Equal(uint8(0xFE), sim.Port(0x01))
+}
+
+func TestAddBoardCustom(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	oldSchema := boarddefinition.Schema
+	boarddefinition.Schema, _ = filepath.Abs("../../schemas/boarddefinition.schema.json")
+	defer func() { boarddefinition.Schema = oldSchema }()
+	definitionFile, _ := filepath.Abs("../../test/data/boarddefinitions/carrier_2xPCA9501.json")
+	sim := simadaptor.NewAdaptor()
+	api := NewBoardsAPI(sim)
+	// act
+	err := api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x02, Type: "Custom",
+		Definition: definitionFile})
+	// assert
+	require.Nil(err)
+	assert.Equal(20, len(api.GetFreePins("TestBoard")))
+	require.Equal(2, len(api.GobotDevices()))
+	output, _ := api.GetOutputPin("TestBoard", 8)
+	assert.Nil(output.WriteValue(0))
+	assert.Equal(uint8(0xFE), sim.Port(0x03))
+}
+
+func TestAddBoardWithMemoryRange(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(simadaptor.NewAdaptor())
+	// act
+	err := api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x01, Type: "Type2io",
+		MemoryFrom: 0x10, MemoryTo: 0x1F})
+	// assert
+	require.Nil(err)
+	assert.Equal(8+16, len(api.GetFreePins("TestBoard")))
+	require.Equal(1, len(api.GobotDevices()))
+	require.Nil(api.GobotDevices()[0].Start())
+	output, err := api.GetOutputPin("TestBoard", 23)
+	require.Nil(err)
+	assert.Nil(output.WriteValue(0x42))
+	require.Nil(api.ReleasePin("TestBoard", 23))
+	input, err := api.GetInputPin("TestBoard", 23)
+	require.Nil(err)
+	val, err := input.ReadValue()
+	assert.Nil(err)
+	assert.Equal(uint8(0x42), val)
+}
+
+func TestGetStoragePin(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(nil)
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", Type: "Virtual8io"}))
+	// act
+	storage, err := api.GetStoragePin("TestBoard", 9)
+	_, errUsed := api.GetStoragePin("TestBoard", 9)
+	_, errGPIO := api.GetStoragePin("TestBoard", 1)
+	_, errBoard := api.GetStoragePin("Unknown", 9)
+	// assert
+	require.Nil(err)
+	require.Nil(storage.WriteValue(1))
+	val, errRead := storage.ReadValue()
+	assert.Nil(errRead)
+	assert.Equal(uint8(1), val)
+	assert.Contains(api.GetUsedPins("TestBoard"), uint8(9))
+	require.NotNil(errUsed)
+	assert.Contains(errUsed.Error(), "already used")
+	require.NotNil(errGPIO)
+	assert.Contains(errGPIO.Error(), "not a readable and writable memory")
+	require.NotNil(errBoard)
+	assert.Contains(errBoard.Error(), "not found")
+}
+
+func TestGetAnalogOutputPin(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(new(adaptorMock))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardPWM", ChipDevAddr: 0x40, Type: "PCA9685"}))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardGPIO", Type: "Virtual8io"}))
+	// act
+	output, err := api.GetAnalogOutputPin("TestBoardPWM", 3)
+	_, errUsed := api.GetAnalogOutputPin("TestBoardPWM", 3)
+	_, errGPIO := api.GetAnalogOutputPin("TestBoardGPIO", 1)
+	_, errBoard := api.GetAnalogOutputPin("Unknown", 3)
+	// assert
+	require.Nil(err)
+	assert.Equal(uint8(3), output.BoardPinNr)
+	assert.Contains(api.GetUsedPins("TestBoardPWM"), uint8(3))
+	require.NotNil(errUsed)
+	assert.Contains(errUsed.Error(), "already used")
+	require.NotNil(errGPIO)
+	assert.Contains(errGPIO.Error(), "not an analog output")
+	assert.NotContains(api.GetUsedPins("TestBoardGPIO"), uint8(1))
+	require.NotNil(errBoard)
+	assert.Contains(errBoard.Error(), "not found")
+}
+
+func TestGetServoOutputPin(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(new(adaptorMock))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardPWM", ChipDevAddr: 0x40, Type: "PCA9685"}))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardGPIO", Type: "Virtual8io"}))
+	// act
+	output, err := api.GetServoOutputPin("TestBoardPWM", 3)
+	_, errUsed := api.GetServoOutputPin("TestBoardPWM", 3)
+	_, errGPIO := api.GetServoOutputPin("TestBoardGPIO", 1)
+	_, errBoard := api.GetServoOutputPin("Unknown", 3)
+	// assert
+	require.Nil(err)
+	assert.Equal(uint8(3), output.BoardPinNr)
+	assert.Contains(api.GetUsedPins("TestBoardPWM"), uint8(3))
+	require.NotNil(errUsed)
+	assert.Contains(errUsed.Error(), "already used")
+	require.NotNil(errGPIO)
+	assert.Contains(errGPIO.Error(), "not a servo output")
+	assert.NotContains(api.GetUsedPins("TestBoardGPIO"), uint8(1))
+	require.NotNil(errBoard)
+	assert.Contains(errBoard.Error(), "not found")
+	assert.Nil(api.ReleasePin("TestBoardPWM", 3))
+}
+
+func TestAddBoardCustomWithoutDefinitionFileGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(simadaptor.NewAdaptor())
+	// act
+	err := api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x02, Type: "Custom",
+		Definition: "not_there.json"})
+	// assert
+	require.NotNil(err)
+	assert.Equal(0, len(api.boards))
+}
+
+func TestAddBoardWithMultiplexerWithoutAdaptorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(nil)
+	// act
+	err := api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x01, Type: "Type2o", MuxAddr: 0x70})
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "needs an adaptor")
+	assert.Equal(0, len(api.boards))
+}
+
+func TestDiscoverBoardsSkipsAllChipsOfCustomBoard(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	oldSchema := boarddefinition.Schema
+	boarddefinition.Schema, _ = filepath.Abs("../../schemas/boarddefinition.schema.json")
+	defer func() { boarddefinition.Schema = oldSchema }()
+	definitionFile, _ := filepath.Abs("../../test/data/boarddefinitions/carrier_2xPCA9501.json")
+	api := NewBoardsAPI(simadaptor.NewAdaptor())
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x02, Type: "Custom",
+		Definition: definitionFile}))
+	// act
+	recipes, err := api.DiscoverBoards()
+	// assert
+	require.Nil(err)
+	require.Equal(0x3E, len(recipes))
+	for _, recipe := range recipes {
+		assert.NotEqual(uint8(0x02), recipe.ChipDevAddr)
+		assert.NotEqual(uint8(0x03), recipe.ChipDevAddr)
+	}
+	assert.Equal(uint8(0x04), recipes[2].ChipDevAddr)
+}
+
+func TestDiscoverBoardsIgnoresBoardsBehindMultiplexer(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(simadaptor.NewAdaptor())
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", ChipDevAddr: 0x04, Type: "Type2io", MuxAddr: 0x71}))
+	// act
+	recipes, err := api.DiscoverBoards()
+	// assert
+	require.Nil(err)
+	require.Equal(0x40, len(recipes))
+	assert.Equal(uint8(0x04), recipes[4].ChipDevAddr)
+}
+
+func TestVerifyBoardIdentities(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	sim := simadaptor.NewAdaptor()
+	api := NewBoardsAPI(sim)
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardOk", ChipDevAddr: 0x04, Type: "Type2io"}))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardSwapped", ChipDevAddr: 0x05, Type: "Type2o"}))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardUnknown", ChipDevAddr: 0x06, Type: "Type2i"}))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardNoMemory", ChipDevAddr: 0x20, Type: "PCF8574i"}))
+	for _, device := range api.GobotDevices() {
+		require.Nil(device.Start())
+	}
+	require.Nil(api.WriteBoardIdentity("TestBoardOk"))
+	require.Nil(api.boards["TestBoardSwapped"].WriteIdentity(board.Identity{Name: "Other", Type: "Type2i"}))
+	// act
+	unknownBoards, err := api.VerifyBoardIdentities()
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "'TestBoardSwapped' (Type2o) at address 0x05 has identity of board 'Other' (Type2i)")
+	assert.NotContains(err.Error(), "TestBoardOk")
+	assert.Equal([]string{"TestBoardUnknown"}, unknownBoards)
+}
+
+func TestRecipesWithMemory(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(new(adaptorMock))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardMemory", ChipDevAddr: 0x04, Type: "Type2io"}))
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoardNoMemory", ChipDevAddr: 0x40, Type: "PCA9685"}))
+	// act
+	recipes := api.RecipesWithMemory()
+	// assert
+	require.Equal(1, len(recipes))
+	assert.Equal("TestBoardMemory", recipes[0].Name)
+}
+
+func TestWriteBoardIdentityUnknownBoardGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	api := NewBoardsAPI(simadaptor.NewAdaptor())
+	// act
+	err := api.WriteBoardIdentity("NotThere")
+	// assert
+	assert.NotNil(err)
+}
+
+func TestDiscoverBoardsUsesIdentity(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	sim := simadaptor.NewAdaptor()
+	boardWithIdentity := board.NewBoardType2io(sim, 0x07, "Identified")
+	require.Nil(boardWithIdentity.GobotDevices()[0].Start())
+	require.Nil(boardWithIdentity.WriteIdentity(board.Identity{Name: "Identified", Type: "Type2io"}))
+	api := NewBoardsAPI(sim)
+	// act
+	recipes, err := api.DiscoverBoards()
+	// assert
+	require.Nil(err)
+	require.Equal(0x40, len(recipes))
+	assert.Equal("Identified", recipes[7].Name)
+	assert.Equal("Type2io", recipes[7].Type)
+	assert.Equal(uint8(0x07), recipes[7].ChipDevAddr)
+	assert.Equal("Board_0x06", recipes[6].Name)
+}
+
 func TestGetInputPin(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
@@ -278,17 +639,136 @@ func TestGetOutputPinWhenBoardNotInitializedGetsError(t *testing.T) {
 	assert.Contains(err.Error(), "not initialized")
 }
 
+func TestReleasePin(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := &BoardsAPI{
+		boards:   make(BoardsMap),
+		usedPins: make(map[string]boardpin.PinNumbers),
+	}
+	api.boards["TestBoard"] = &boardsMock{name: "TestBoard", binPins: 3}
+	api.usedPins["TestBoard"] = make(boardpin.PinNumbers)
+	_, _ = api.GetOutputPin("TestBoard", 1)
+	_, _ = api.GetInputPin("TestBoard", 2)
+	// act
+	err := api.ReleasePin("TestBoard", 1)
+	// assert
+	require.Nil(err)
+	assert.Equal(boardpin.PinNumbers{2: struct{}{}}, api.GetUsedPins("TestBoard"))
+	assert.Contains(api.GetFreePins("TestBoard"), uint8(1))
+	pin, errReuse := api.GetInputPin("TestBoard", 1)
+	assert.Nil(errReuse)
+	assert.NotNil(pin)
+}
+
+func TestReleasePinWhenNotUsedGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := &BoardsAPI{
+		boards:   make(BoardsMap),
+		usedPins: make(map[string]boardpin.PinNumbers),
+	}
+	api.boards["TestBoard"] = &boardsMock{name: "TestBoard", binPins: 3}
+	api.usedPins["TestBoard"] = make(boardpin.PinNumbers)
+	// act
+	err := api.ReleasePin("TestBoard", 1)
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "is not used")
+}
+
+func TestReleasePinWhenBoardUnknownGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := &BoardsAPI{
+		boards:   make(BoardsMap),
+		usedPins: make(map[string]boardpin.PinNumbers),
+	}
+	// act
+	err := api.ReleasePin("UnknownBoard", 1)
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "not found")
+}
+
+func TestConcurrentUse(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	api := NewBoardsAPI(nil)
+	require.Nil(api.AddBoard(boardrecipe.Ingredients{Name: "TestBoard", Type: "Virtual8io"}))
+	output, err := api.GetOutputPin("TestBoard", 0)
+	require.Nil(err)
+	input, err := api.GetInputPin("TestBoard", 1)
+	require.Nil(err)
+	var wg sync.WaitGroup
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(4)
+		go func(value uint8) {
+			defer wg.Done()
+			output.WriteValue(value)
+			input.ReadValue()
+		}(uint8(i % 2))
+		go func() {
+			defer wg.Done()
+			api.BeginCycle()
+			api.EndCycle()
+		}()
+		go func(boardID string) {
+			defer wg.Done()
+			api.AddBoard(boardrecipe.Ingredients{Name: boardID, Type: "Virtual8io"})
+			api.GetInputPin(boardID, 2)
+			api.GetFreePins(boardID)
+			api.ReleasePin(boardID, 2)
+			api.RemoveBoard(boardID)
+		}(fmt.Sprintf("TestBoard%d", i))
+		go func() {
+			defer wg.Done()
+			api.GetUsedPins("TestBoard")
+			api.GobotDevices()
+			api.RecipesWithMemory()
+		}()
+	}
+	wg.Wait()
+	// assert
+	assert.Equal(boardpin.PinNumbers{0: struct{}{}, 1: struct{}{}}, api.GetUsedPins("TestBoard"))
+	assert.Equal(1, len(api.boards))
+}
+
 func (a *adaptorMock) GetConnection(address int, bus int) (device i2c.Connection, err error) { return }
 func (a *adaptorMock) GetDefaultBus() int                                                    { return 0 }
 
 func (b boardsMock) GobotDevices() []gobot.Device { return nil }
+func (b boardsMock) ChipAddresses() []uint8       { return nil }
 func (b boardsMock) GetPinNumbers() boardpin.PinNumbers {
 	return createPinNumbersMap(b.binPins + b.anaPins + b.memPins)
 }
-func (b boardsMock) GetPinNumbersOfType(boardpin.PinType) boardpin.PinNumbers { return nil }
-func (b boardsMock) ReadValue(boardPinNr uint8) (uint8, error)                { return 0, nil }
-func (b boardsMock) WriteValue(boardPinNr uint8, value uint8) (err error)     { return }
-func (b boardsMock) ShowBoardConfig()                                         { return }
+func (b boardsMock) GetPinNumbersOfType(...boardpin.PinType) boardpin.PinNumbers { return nil }
+func (b boardsMock) ReadValue(boardPinNr uint8) (uint8, error)                   { return 0, nil }
+func (b boardsMock) WriteValue(boardPinNr uint8, value uint8) (err error)        { return }
+func (b boardsMock) ShowBoardConfig()                                            { return }
+func (b boardsMock) SetCoalescedWrites(coalesce bool)                            { return }
+func (b boardsMock) SetServoMode(boardPinNr uint8, servo bool) (err error)       { return }
+func (b boardsMock) HasMemory() bool                                             { return false }
+func (b boardsMock) SetMemoryRange(from uint8, to uint8) (err error)             { return }
+func (b boardsMock) ReadIdentity() (board.Identity, bool, error)                 { return board.Identity{}, false, nil }
+func (b boardsMock) WriteIdentity(identity board.Identity) (err error)           { return }
+func (b boardsMock) FlushOutputs() (err error) {
+	if b.flushed != nil {
+		*b.flushed = true
+	}
+	return
+}
+func (b boardsMock) ExpireInputs() {
+	if b.expired != nil {
+		*b.expired = true
+	}
+}
+func (b boardsMock) ExpireOutputs() { return }
 
 func createPinNumbersMap(pinCount uint8) (pinNumbers boardpin.PinNumbers) {
 	pinNumbers = make(boardpin.PinNumbers)
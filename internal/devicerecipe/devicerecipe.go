@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gen2thomas/gobrail/internal/errwrap"
@@ -31,15 +33,35 @@ const (
 	TwoLightsSignal
 	// Turnout is a output device with two outputs
 	Turnout
+	// SemaphoreSignal is a output device with two outputs for coils and an optional auxiliary output
+	SemaphoreSignal
+	// ThreeLightsSignal is a output device with three outputs, the combination of lamps is given by named aspects
+	ThreeLightsSignal
+	// FourLightsSignal is a output device with four outputs, the combination of lamps is given by named aspects
+	FourLightsSignal
+	// BlinkingLamp is a output device with one output, which is blinking while on
+	BlinkingLamp
+	// CrossingFlasher is a output device with two outputs, which are blinking alternating while on
+	CrossingFlasher
+	// DimmableLamp is a output device with one analog output, fading in and out
+	DimmableLamp
+	// ServoTurnout is a output device with one analog output for a servo
+	ServoTurnout
 )
 
 // TypeMap is the string representation to the underlying "railDeviceType"
 var TypeMap = map[string]railDeviceType{
 	"Button": Button, "ToggleButton": ToggleButton,
-	"Lamp": Lamp, "TwoLightsSignal": TwoLightsSignal, "Turnout": Turnout,
+	"Lamp": Lamp, "TwoLightsSignal": TwoLightsSignal, "SemaphoreSignal": SemaphoreSignal,
+	"ThreeLightsSignal": ThreeLightsSignal, "FourLightsSignal": FourLightsSignal,
+	"BlinkingLamp": BlinkingLamp, "CrossingFlasher": CrossingFlasher, "DimmableLamp": DimmableLamp,
+	"Turnout": Turnout, "ServoTurnout": ServoTurnout,
 	"TypUnknown": TypUnknown,
 }
 
+// persistableTypes are all types, which keep their position without power, so the state can be persisted
+var persistableTypes = map[railDeviceType]bool{Turnout: true, SemaphoreSignal: true, ServoTurnout: true}
+
 // Ingredients describes a recipe to create an new rail device
 type Ingredients struct {
 	Name           string `json:"Name"`
@@ -51,8 +73,39 @@ type Ingredients struct {
 	StoppingDelay  string `json:"StoppingDelay"`
 	Connect        string `json:"Connect"`
 	Inverse        bool   `json:"Inverse"`
+	SafeState      string `json:"SafeState,omitempty"`
+	Persist        bool   `json:"Persist,omitempty"`
+	BoardPinNrMem  uint8  `json:"BoardPinNrMem,omitempty"`
+	AuxOutput      bool   `json:"AuxOutput,omitempty"`
+	BoardPinNrAux  uint8  `json:"BoardPinNrAux,omitempty"`
+	// for signals with named aspects only
+	BoardPinNrThird  uint8             `json:"BoardPinNrThird,omitempty"`
+	BoardPinNrFourth uint8             `json:"BoardPinNrFourth,omitempty"`
+	Aspects          map[string]string `json:"Aspects,omitempty"`
+	AspectOn         string            `json:"AspectOn,omitempty"`
+	AspectOff        string            `json:"AspectOff,omitempty"`
+	// for blinking devices only
+	OnPeriod  string `json:"OnPeriod,omitempty"`
+	OffPeriod string `json:"OffPeriod,omitempty"`
+	// for servo turnouts only, the positions are the values written to the analog output
+	PositionMain   uint8 `json:"PositionMain,omitempty"`
+	PositionBranch uint8 `json:"PositionBranch,omitempty"`
+	TravelSpeed    uint8 `json:"TravelSpeed,omitempty"`
+	// for turnouts only, the inputs of the limit switches at the same board
+	FeedbackBranch     bool   `json:"FeedbackBranch,omitempty"`
+	BoardPinNrFbBranch uint8  `json:"BoardPinNrFbBranch,omitempty"`
+	FeedbackMain       bool   `json:"FeedbackMain,omitempty"`
+	BoardPinNrFbMain   uint8  `json:"BoardPinNrFbMain,omitempty"`
+	FeedbackTimeout    string `json:"FeedbackTimeout,omitempty"`
 }
 
+const (
+	// SafeStateOff switches all outputs of the device off, this is the default
+	SafeStateOff = "Off"
+	// SafeStateLast keeps all outputs of the device in the last state
+	SafeStateLast = "Last"
+)
+
 // TODO: can write json single object description from a a plan-object
 
 // ReadIngredients is parsing json device description to a device recipe
@@ -88,6 +141,47 @@ func (r Ingredients) EnhanceAndVerify() (err error) {
 	if _, ok := TypeMap[r.Type]; !ok {
 		err = fmt.Errorf("The given type '%s' is unknown", r.Type)
 	}
+	if r.Persist && !persistableTypes[TypeMap[r.Type]] {
+		err = fmt.Errorf("The state can not be persisted for type '%s'", r.Type)
+	}
+	if r.AuxOutput && TypeMap[r.Type] != SemaphoreSignal {
+		err = fmt.Errorf("An auxiliary output can not be used for type '%s'", r.Type)
+	}
+	if (len(r.Aspects) > 0 || r.AspectOn != "" || r.AspectOff != "") && !r.HasAspects() {
+		err = fmt.Errorf("Aspects can not be used for type '%s'", r.Type)
+	}
+	if _, err1 := ParseAspects(r.Aspects); err1 != nil {
+		err = err1
+	}
+	if (r.OnPeriod != "" || r.OffPeriod != "") && !r.IsBlinking() {
+		err = fmt.Errorf("Periods for blinking can not be used for type '%s'", r.Type)
+	}
+	for _, period := range []string{r.OnPeriod, r.OffPeriod} {
+		if period == "" {
+			continue
+		}
+		duration, err1 := time.ParseDuration(period)
+		if err1 != nil {
+			err = fmt.Errorf("The given period '%s' is not parsable, %w", period, err1)
+		} else if duration <= 0 {
+			err = fmt.Errorf("The given period '%s' needs to be greater than zero", period)
+		}
+	}
+
+	if (r.HasFeedback() || r.FeedbackTimeout != "") && TypeMap[r.Type] != Turnout {
+		err = fmt.Errorf("A feedback can not be used for type '%s'", r.Type)
+	}
+	if r.FeedbackTimeout != "" {
+		if _, err1 := time.ParseDuration(r.FeedbackTimeout); err1 != nil {
+			err = fmt.Errorf("The given feedback timeout '%s' is not parsable, %w", r.FeedbackTimeout, err1)
+		}
+	}
+	if TypeMap[r.Type] == ServoTurnout && r.PositionMain == r.PositionBranch {
+		err = fmt.Errorf("The positions for main and branch must differ for type '%s'", r.Type)
+	}
+	if (r.PositionMain != 0 || r.PositionBranch != 0 || r.TravelSpeed != 0) && TypeMap[r.Type] != ServoTurnout {
+		err = fmt.Errorf("Servo positions and speed can not be used for type '%s'", r.Type)
+	}
 
 	if _, err1 := time.ParseDuration(r.StartingDelay); err1 != nil {
 		err = fmt.Errorf("The given start delay '%s' is not parsable, %w", r.StartingDelay, err)
@@ -95,10 +189,80 @@ func (r Ingredients) EnhanceAndVerify() (err error) {
 	if _, err1 := time.ParseDuration(r.StoppingDelay); err1 != nil {
 		err = fmt.Errorf("The given stop delay '%s' is not parsable, %w", r.StoppingDelay, err)
 	}
+	if _, _, err1 := ParseSafeState(r.SafeState); err1 != nil {
+		err = fmt.Errorf("The given safe state '%s' is not parsable, %w", r.SafeState, err1)
+	}
 
 	return
 }
 
+// HasAspects states true for all types with named aspects
+func (r Ingredients) HasAspects() bool {
+	return TypeMap[r.Type] == ThreeLightsSignal || TypeMap[r.Type] == FourLightsSignal
+}
+
+// IsBlinking states true for all types with blinking outputs
+func (r Ingredients) IsBlinking() bool {
+	return TypeMap[r.Type] == BlinkingLamp || TypeMap[r.Type] == CrossingFlasher
+}
+
+// HasFeedback states true, when at least one end position is verified by a feedback input
+func (r Ingredients) HasFeedback() bool {
+	return r.FeedbackBranch || r.FeedbackMain
+}
+
+// FeedbackBoardPins gets the board pin numbers of all used feedback inputs
+func (r Ingredients) FeedbackBoardPins() (boardPinNrs []uint8) {
+	if r.FeedbackBranch {
+		boardPinNrs = append(boardPinNrs, r.BoardPinNrFbBranch)
+	}
+	if r.FeedbackMain {
+		boardPinNrs = append(boardPinNrs, r.BoardPinNrFbMain)
+	}
+	return
+}
+
+// ParseAspects gets the values of each named aspect, e.g. "Hp2": "0,1,1" for a signal with three lights
+func ParseAspects(aspects map[string]string) (parsed map[string][]uint8, err error) {
+	parsed = make(map[string][]uint8)
+	for aspect, valuesTxt := range aspects {
+		var values []uint8
+		if values, err = parseValues(valuesTxt); err != nil {
+			return nil, fmt.Errorf("The aspect '%s' is not parsable, %w", aspect, err)
+		}
+		parsed[aspect] = values
+	}
+	return
+}
+
+// ParseSafeState gets the values to write to the outputs of the device for the safe state, e.g. "0,1" for a signal
+// a single value is used for all outputs, "Off" (or empty) leads to no values and "Last" to keep the last state
+func ParseSafeState(safeState string) (values []uint8, keepLast bool, err error) {
+	switch safeState {
+	case "", SafeStateOff:
+		return
+	case SafeStateLast:
+		keepLast = true
+		return
+	}
+	if values, err = parseValues(safeState); err != nil {
+		return nil, false, err
+	}
+	return
+}
+
+// parseValues gets the values of a comma separated list, e.g. "0, 1,255"
+func parseValues(valuesTxt string) (values []uint8, err error) {
+	for _, valueTxt := range strings.Split(valuesTxt, ",") {
+		var value uint64
+		if value, err = strconv.ParseUint(strings.TrimSpace(valueTxt), 10, 8); err != nil {
+			return nil, err
+		}
+		values = append(values, uint8(value))
+	}
+	return
+}
+
 // fillEmptyDefaults will correct some optional values after parsing
 func (r *Ingredients) fillEmptyDefaults() {
 	if r.StartingDelay == "" {
@@ -107,10 +271,40 @@ func (r *Ingredients) fillEmptyDefaults() {
 	if r.StoppingDelay == "" {
 		r.StoppingDelay = "0"
 	}
+	if r.SafeState == "" {
+		r.SafeState = SafeStateOff
+	}
 	return
 }
 
 func (r Ingredients) String() string {
-	return fmt.Sprintf("Name: %s, Type: %s, BoardID: %s, BoardPinNrPrim: %d, BoardPinNrSecond: %d, StartingDelay: %s, StoppingDelay: %s, Connect: %s, Inverse: %t",
-		r.Name, r.Type, r.BoardID, r.BoardPinNrPrim, r.BoardPinNrSec, r.StartingDelay, r.StoppingDelay, r.Connect, r.Inverse)
+	toString := fmt.Sprintf("Name: %s, Type: %s, BoardID: %s, BoardPinNrPrim: %d, BoardPinNrSecond: %d, StartingDelay: %s, StoppingDelay: %s, Connect: %s, Inverse: %t, SafeState: %s",
+		r.Name, r.Type, r.BoardID, r.BoardPinNrPrim, r.BoardPinNrSec, r.StartingDelay, r.StoppingDelay, r.Connect, r.Inverse, r.SafeState)
+	if r.Persist {
+		toString = fmt.Sprintf("%s, BoardPinNrMem: %d", toString, r.BoardPinNrMem)
+	}
+	if r.AuxOutput {
+		toString = fmt.Sprintf("%s, BoardPinNrAux: %d", toString, r.BoardPinNrAux)
+	}
+	if r.FeedbackBranch {
+		toString = fmt.Sprintf("%s, BoardPinNrFbBranch: %d", toString, r.BoardPinNrFbBranch)
+	}
+	if r.FeedbackMain {
+		toString = fmt.Sprintf("%s, BoardPinNrFbMain: %d", toString, r.BoardPinNrFbMain)
+	}
+	if r.HasFeedback() {
+		toString = fmt.Sprintf("%s, FeedbackTimeout: %s", toString, r.FeedbackTimeout)
+	}
+	if r.HasAspects() {
+		toString = fmt.Sprintf("%s, BoardPinNrThird: %d, BoardPinNrFourth: %d, Aspects: %v, AspectOn: %s, AspectOff: %s",
+			toString, r.BoardPinNrThird, r.BoardPinNrFourth, r.Aspects, r.AspectOn, r.AspectOff)
+	}
+	if r.IsBlinking() {
+		toString = fmt.Sprintf("%s, OnPeriod: %s, OffPeriod: %s", toString, r.OnPeriod, r.OffPeriod)
+	}
+	if TypeMap[r.Type] == ServoTurnout {
+		toString = fmt.Sprintf("%s, PositionMain: %d, PositionBranch: %d, TravelSpeed: %d", toString, r.PositionMain,
+			r.PositionBranch, r.TravelSpeed)
+	}
+	return toString
 }
@@ -26,6 +26,27 @@ func TestEnhanceAndVerify(t *testing.T) {
 		"WrongStartDelay": {di: Ingredients{Type: "Button", StartingDelay: "WrongStartDelay"}, wantErr: "start delay 'WrongStartDelay' is not parsable"},
 		"WrongStopDelay":  {di: Ingredients{Type: "Button", StartingDelay: "1m", StoppingDelay: "WrongStopDelay"}, wantErr: "stop delay 'WrongStopDelay' is not parsable"},
 		"NoError":         {di: Ingredients{Type: "Button", StartingDelay: "1m", StoppingDelay: "1s"}},
+		"WrongSafeState":  {di: Ingredients{Type: "Lamp", SafeState: "On"}, wantErr: "safe state 'On' is not parsable"},
+		"SafeStateValues": {di: Ingredients{Type: "TwoLightsSignal", SafeState: "0, 1"}},
+		"Persist":         {di: Ingredients{Type: "Turnout", Persist: true, BoardPinNrMem: 8}},
+		"AuxOutput":       {di: Ingredients{Type: "SemaphoreSignal", AuxOutput: true, BoardPinNrAux: 4}},
+		"AuxOutputLamp":   {di: Ingredients{Type: "Lamp", AuxOutput: true}, wantErr: "auxiliary output can not be used for type 'Lamp'"},
+		"Aspects":         {di: Ingredients{Type: "ThreeLightsSignal", Aspects: map[string]string{"Hp0": "1,0,0"}, AspectOff: "Hp0"}},
+		"AspectsLamp":     {di: Ingredients{Type: "Lamp", AspectOn: "Hp1"}, wantErr: "Aspects can not be used for type 'Lamp'"},
+		"WrongAspects":    {di: Ingredients{Type: "FourLightsSignal", Aspects: map[string]string{"Sh1": "1,x"}}, wantErr: "aspect 'Sh1' is not parsable"},
+		"Periods":         {di: Ingredients{Type: "CrossingFlasher", OnPeriod: "400ms", OffPeriod: "0.6s"}},
+		"PeriodsLamp":     {di: Ingredients{Type: "Lamp", OnPeriod: "1s"}, wantErr: "Periods for blinking can not be used for type 'Lamp'"},
+		"WrongPeriod":     {di: Ingredients{Type: "BlinkingLamp", OffPeriod: "1x"}, wantErr: "period '1x' is not parsable"},
+		"ZeroPeriod":      {di: Ingredients{Type: "BlinkingLamp", OnPeriod: "0s"}, wantErr: "period '0s' needs to be greater"},
+		"NegativePeriod":  {di: Ingredients{Type: "CrossingFlasher", OffPeriod: "-1s"}, wantErr: "period '-1s' needs to be greater"},
+		"Servo":           {di: Ingredients{Type: "ServoTurnout", PositionMain: 20, PositionBranch: 40, TravelSpeed: 10}},
+		"ServoPositions":  {di: Ingredients{Type: "ServoTurnout", PositionMain: 20, PositionBranch: 20}, wantErr: "positions for main and branch must differ"},
+		"ServoTurnout":    {di: Ingredients{Type: "Turnout", TravelSpeed: 10}, wantErr: "Servo positions and speed can not be used for type 'Turnout'"},
+		"Feedback":        {di: Ingredients{Type: "Turnout", FeedbackBranch: true, BoardPinNrFbBranch: 2, FeedbackMain: true, BoardPinNrFbMain: 3, FeedbackTimeout: "2s"}},
+		"FeedbackMain":    {di: Ingredients{Type: "Turnout", FeedbackMain: true, BoardPinNrFbMain: 0}},
+		"FeedbackServo":   {di: Ingredients{Type: "ServoTurnout", PositionMain: 1, FeedbackBranch: true}, wantErr: "feedback can not be used for type 'ServoTurnout'"},
+		"WrongFbTimeout":  {di: Ingredients{Type: "Turnout", FeedbackMain: true, FeedbackTimeout: "2"}, wantErr: "feedback timeout '2' is not parsable"},
+		"PersistLamp":     {di: Ingredients{Type: "Lamp", Persist: true, BoardPinNrMem: 8}, wantErr: "state can not be persisted for type 'Lamp'"},
 	}
 	for name, vt := range verifyTests {
 		t.Run(name, func(t *testing.T) {
@@ -45,11 +66,49 @@ func TestEnhanceAndVerify(t *testing.T) {
 	}
 }
 
+func TestParseSafeState(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act
+	valuesEmpty, keepEmpty, errEmpty := ParseSafeState("")
+	valuesOff, keepOff, errOff := ParseSafeState("Off")
+	valuesLast, keepLast, errLast := ParseSafeState("Last")
+	values, keep, err := ParseSafeState("0, 1,255")
+	_, _, errRange := ParseSafeState("256")
+	// assert
+	assert.Nil(errEmpty)
+	assert.Nil(valuesEmpty)
+	assert.False(keepEmpty)
+	assert.Nil(errOff)
+	assert.Nil(valuesOff)
+	assert.False(keepOff)
+	assert.Nil(errLast)
+	assert.Nil(valuesLast)
+	assert.True(keepLast)
+	assert.Nil(err)
+	assert.Equal([]uint8{0, 1, 255}, values)
+	assert.False(keep)
+	assert.NotNil(errRange)
+}
+
+func TestParseAspects(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	// act
+	aspects, err := ParseAspects(map[string]string{"Hp0": "1,0,0", "Hp2": "0, 1, 1"})
+	_, errParse := ParseAspects(map[string]string{"Hp0": "1,,0"})
+	// assert
+	assert.Nil(err)
+	assert.Equal(map[string][]uint8{"Hp0": {1, 0, 0}, "Hp2": {0, 1, 1}}, aspects)
+	assert.NotNil(errParse)
+}
+
 func Test_fillEmptyDefaults(t *testing.T) {
 	var fillTests = map[string]fillTest{
-		"EmptyStart": {di: Ingredients{StartingDelay: "", StoppingDelay: "1m"}, want: Ingredients{StartingDelay: "0", StoppingDelay: "1m"}},
-		"EmptyStop":  {di: Ingredients{StartingDelay: "2h", StoppingDelay: ""}, want: Ingredients{StartingDelay: "2h", StoppingDelay: "0"}},
-		"NoFill":     {di: Ingredients{StartingDelay: "2h1m", StoppingDelay: "3m2s"}, want: Ingredients{StartingDelay: "2h1m", StoppingDelay: "3m2s"}},
+		"EmptyStart": {di: Ingredients{StartingDelay: "", StoppingDelay: "1m", SafeState: "Last"}, want: Ingredients{StartingDelay: "0", StoppingDelay: "1m", SafeState: "Last"}},
+		"EmptyStop":  {di: Ingredients{StartingDelay: "2h", StoppingDelay: "", SafeState: "1"}, want: Ingredients{StartingDelay: "2h", StoppingDelay: "0", SafeState: "1"}},
+		"EmptySafe":  {di: Ingredients{StartingDelay: "2h", StoppingDelay: "1m"}, want: Ingredients{StartingDelay: "2h", StoppingDelay: "1m", SafeState: "Off"}},
+		"NoFill":     {di: Ingredients{StartingDelay: "2h1m", StoppingDelay: "3m2s", SafeState: "Off"}, want: Ingredients{StartingDelay: "2h1m", StoppingDelay: "3m2s", SafeState: "Off"}},
 	}
 	for name, ft := range fillTests {
 		t.Run(name, func(t *testing.T) {
@@ -60,6 +119,7 @@ func Test_fillEmptyDefaults(t *testing.T) {
 			// assert
 			assert.Equal(ft.want.StartingDelay, ft.di.StartingDelay)
 			assert.Equal(ft.want.StoppingDelay, ft.di.StoppingDelay)
+			assert.Equal(ft.want.SafeState, ft.di.SafeState)
 		})
 	}
 }
@@ -0,0 +1,183 @@
+package i2cmux
+
+// Implementation of a TCA9548A i2c multiplexer to use the same chip addresses more than once
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: boardsapi
+// Call       : i2c connector of the gobot adaptor
+//
+// The TCA9548A connects the bus of the adaptor with up to 8 channels by writing a bit mask to its control register.
+// Chips at different channels can have the same address, therefore the channel is selected before each transaction
+// and deselected afterwards, so no other transaction can reach a chip behind the multiplexer by accident.
+//
+// Functions:
+// + provide an i2c connector for each channel, which is also a gobot connection
+// + select the channel before each transaction and deselect afterwards
+//
+
+import (
+	"fmt"
+	"sync"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+
+	"github.com/gen2thomas/gobrail/internal/errwrap"
+)
+
+const maxChannel = 7
+
+// Multiplexer is a TCA9548A at the bus of the adaptor
+type Multiplexer struct {
+	adaptor i2c.Connector
+	address uint8
+	control i2c.Connection
+	mutex   *sync.Mutex
+}
+
+type channelConnector struct {
+	name    string
+	mux     *Multiplexer
+	channel uint8
+}
+
+type channelConnection struct {
+	connector  *channelConnector
+	connection i2c.Connection
+	bus        int
+}
+
+// NewMultiplexer creates a new multiplexer at the given address of the adaptor bus
+func NewMultiplexer(adaptor i2c.Connector, address uint8) *Multiplexer {
+	return &Multiplexer{
+		adaptor: adaptor,
+		address: address,
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Channel gets a connector, which routes all traffic over the given channel of the multiplexer
+func (m *Multiplexer) Channel(channel uint8) (connector i2c.Connector, err error) {
+	if channel > maxChannel {
+		return nil, fmt.Errorf("The given channel %d is out of range 0..%d", channel, maxChannel)
+	}
+	name := gobot.DefaultName(fmt.Sprintf("TCA9548A_0x%02X_Channel%d", m.address, channel))
+	return &channelConnector{name: name, mux: m, channel: channel}, nil
+}
+
+// transaction selects the channel, calls the function and deselects all channels afterwards
+func (m *Multiplexer) transaction(channel uint8, bus int, transactionFunc func() (err error)) (err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.control == nil {
+		if m.control, err = m.adaptor.GetConnection(int(m.address), bus); err != nil {
+			return fmt.Errorf("Multiplexer at address 0x%02X not reachable, %w", m.address, err)
+		}
+	}
+	if err = m.control.WriteByte(1 << channel); err != nil {
+		return fmt.Errorf("Channel %d of multiplexer at address 0x%02X can not be selected, %w", channel, m.address, err)
+	}
+	err = transactionFunc()
+	return errwrap.Wrap(err, m.control.WriteByte(0))
+}
+
+// GetConnection returns a connection to the chip with the given address behind the channel
+func (c *channelConnector) GetConnection(address int, bus int) (connection i2c.Connection, err error) {
+	if connection, err = c.mux.adaptor.GetConnection(address, bus); err != nil {
+		return
+	}
+	return &channelConnection{connector: c, connection: connection, bus: bus}, nil
+}
+
+// GetDefaultBus returns the default bus of the adaptor
+func (c *channelConnector) GetDefaultBus() int {
+	return c.mux.adaptor.GetDefaultBus()
+}
+
+// Name returns the name of the channel
+func (c *channelConnector) Name() string { return c.name }
+
+// SetName sets the name of the channel
+func (c *channelConnector) SetName(name string) { c.name = name }
+
+// Connect does nothing, the adaptor will be connected by itself
+func (c *channelConnector) Connect() (err error) { return }
+
+// Finalize does nothing, the adaptor will be finalized by itself
+func (c *channelConnector) Finalize() (err error) { return }
+
+func (c *channelConnection) transaction(transactionFunc func() (err error)) (err error) {
+	return c.connector.mux.transaction(c.connector.channel, c.bus, transactionFunc)
+}
+
+// Read reads from the chip behind the channel
+func (c *channelConnection) Read(data []byte) (read int, err error) {
+	err = c.transaction(func() (err error) {
+		read, err = c.connection.Read(data)
+		return
+	})
+	return
+}
+
+// Write writes to the chip behind the channel
+func (c *channelConnection) Write(data []byte) (written int, err error) {
+	err = c.transaction(func() (err error) {
+		written, err = c.connection.Write(data)
+		return
+	})
+	return
+}
+
+// Close closes the connection to the chip, the multiplexer is not involved
+func (c *channelConnection) Close() (err error) {
+	return c.connection.Close()
+}
+
+// ReadByte reads a byte from the chip behind the channel
+func (c *channelConnection) ReadByte() (val byte, err error) {
+	err = c.transaction(func() (err error) {
+		val, err = c.connection.ReadByte()
+		return
+	})
+	return
+}
+
+// ReadByteData reads a byte from the register of the chip behind the channel
+func (c *channelConnection) ReadByteData(reg uint8) (val uint8, err error) {
+	err = c.transaction(func() (err error) {
+		val, err = c.connection.ReadByteData(reg)
+		return
+	})
+	return
+}
+
+// ReadWordData reads a word from the register of the chip behind the channel
+func (c *channelConnection) ReadWordData(reg uint8) (val uint16, err error) {
+	err = c.transaction(func() (err error) {
+		val, err = c.connection.ReadWordData(reg)
+		return
+	})
+	return
+}
+
+// WriteByte writes a byte to the chip behind the channel
+func (c *channelConnection) WriteByte(val byte) (err error) {
+	return c.transaction(func() error { return c.connection.WriteByte(val) })
+}
+
+// WriteByteData writes a byte to the register of the chip behind the channel
+func (c *channelConnection) WriteByteData(reg uint8, val uint8) (err error) {
+	return c.transaction(func() error { return c.connection.WriteByteData(reg, val) })
+}
+
+// WriteWordData writes a word to the register of the chip behind the channel
+func (c *channelConnection) WriteWordData(reg uint8, val uint16) (err error) {
+	return c.transaction(func() error { return c.connection.WriteWordData(reg, val) })
+}
+
+// WriteBlockData writes all bytes to the chip behind the channel, beginning at the given register
+func (c *channelConnection) WriteBlockData(reg uint8, b []byte) (err error) {
+	return c.transaction(func() error { return c.connection.WriteBlockData(reg, b) })
+}
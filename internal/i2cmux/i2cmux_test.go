@@ -0,0 +1,192 @@
+package i2cmux
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+var _ i2c.Connector = (*channelConnector)(nil)
+var _ gobot.Connection = (*channelConnector)(nil)
+var _ i2c.Connection = (*channelConnection)(nil)
+
+type busMock struct {
+	log          *[]string
+	mutex        *sync.Mutex
+	simSelectErr bool
+	simConErr    bool
+}
+
+type busConnectionMock struct {
+	bus     *busMock
+	address int
+}
+
+func newBusMock() *busMock {
+	return &busMock{log: &[]string{}, mutex: &sync.Mutex{}}
+}
+
+func TestChannelOutOfRangeGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	mux := NewMultiplexer(newBusMock(), 0x70)
+	// act
+	connector, err := mux.Channel(8)
+	// assert
+	require.NotNil(err)
+	assert.Nil(connector)
+	assert.Contains(err.Error(), "out of range")
+}
+
+func TestChannel(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	mux := NewMultiplexer(newBusMock(), 0x70)
+	// act
+	connector, err := mux.Channel(7)
+	// assert
+	require.Nil(err)
+	require.NotNil(connector)
+	assert.Equal(1, connector.GetDefaultBus())
+	assert.Contains(connector.(gobot.Connection).Name(), "TCA9548A_0x70_Channel7")
+}
+
+func TestTransactionSelectsAndDeselectsChannel(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	bus := newBusMock()
+	mux := NewMultiplexer(bus, 0x71)
+	connector, _ := mux.Channel(2)
+	con, err := connector.GetConnection(0x04, 1)
+	require.Nil(err)
+	// act
+	errWrite := con.WriteByte(0x5A)
+	val, errRead := con.ReadByteData(0x10)
+	// assert
+	require.Nil(errWrite)
+	require.Nil(errRead)
+	assert.Equal(uint8(0x10), val)
+	assert.Equal([]string{
+		"0x71 WriteByte 0x04", "0x04 WriteByte 0x5A", "0x71 WriteByte 0x00",
+		"0x71 WriteByte 0x04", "0x04 ReadByteData 0x10", "0x71 WriteByte 0x00",
+	}, *bus.log)
+}
+
+func TestTransactionWhenSelectErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	bus := newBusMock()
+	bus.simSelectErr = true
+	mux := NewMultiplexer(bus, 0x70)
+	connector, _ := mux.Channel(0)
+	con, _ := connector.GetConnection(0x04, 1)
+	// act
+	_, err := con.ReadByte()
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "can not be selected")
+	assert.Equal([]string{"0x70 WriteByte 0x01"}, *bus.log)
+}
+
+func TestTransactionWhenMultiplexerNotReachableGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	bus := newBusMock()
+	mux := NewMultiplexer(bus, 0x70)
+	connector, _ := mux.Channel(0)
+	con, _ := connector.GetConnection(0x04, 1)
+	bus.simConErr = true
+	// act
+	err := con.WriteWordData(0x02, 0x1234)
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "not reachable")
+	assert.Empty(*bus.log)
+}
+
+func TestTransactionsOfChannelsAreSerialized(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	bus := newBusMock()
+	mux := NewMultiplexer(bus, 0x70)
+	var wg sync.WaitGroup
+	// act
+	for channel := uint8(0); channel <= maxChannel; channel++ {
+		connector, _ := mux.Channel(channel)
+		con, _ := connector.GetConnection(0x04, 1)
+		wg.Add(1)
+		go func(con i2c.Connection) {
+			defer wg.Done()
+			con.WriteByte(0xFF)
+		}(con)
+	}
+	wg.Wait()
+	// assert
+	log := *bus.log
+	assert.Equal(3*(maxChannel+1), len(log))
+	for i := 0; i < len(log); i += 3 {
+		assert.Contains(log[i], "0x70 WriteByte")
+		assert.Equal("0x04 WriteByte 0xFF", log[i+1])
+		assert.Equal("0x70 WriteByte 0x00", log[i+2])
+	}
+}
+
+func (b *busMock) GetConnection(address int, bus int) (connection i2c.Connection, err error) {
+	if b.simConErr {
+		return nil, fmt.Errorf("connection error")
+	}
+	return &busConnectionMock{bus: b, address: address}, nil
+}
+func (b *busMock) GetDefaultBus() int { return 1 }
+
+func (c *busConnectionMock) record(operation string, val uint8) {
+	c.bus.mutex.Lock()
+	defer c.bus.mutex.Unlock()
+
+	*c.bus.log = append(*c.bus.log, fmt.Sprintf("0x%02X %s 0x%02X", c.address, operation, val))
+}
+
+func (c *busConnectionMock) Read(data []byte) (int, error)  { return len(data), nil }
+func (c *busConnectionMock) Write(data []byte) (int, error) { return len(data), nil }
+func (c *busConnectionMock) Close() error                   { return nil }
+func (c *busConnectionMock) ReadByte() (byte, error) {
+	c.record("ReadByte", 0)
+	return 0, nil
+}
+func (c *busConnectionMock) ReadByteData(reg uint8) (uint8, error) {
+	c.record("ReadByteData", reg)
+	return reg, nil
+}
+func (c *busConnectionMock) ReadWordData(reg uint8) (uint16, error) {
+	c.record("ReadWordData", reg)
+	return uint16(reg), nil
+}
+func (c *busConnectionMock) WriteByte(val byte) error {
+	c.record("WriteByte", val)
+	if c.bus.simSelectErr && c.address >= 0x70 {
+		return fmt.Errorf("select error")
+	}
+	return nil
+}
+func (c *busConnectionMock) WriteByteData(reg uint8, val uint8) error {
+	c.record("WriteByteData", reg)
+	return nil
+}
+func (c *busConnectionMock) WriteWordData(reg uint8, val uint16) error {
+	c.record("WriteWordData", reg)
+	return nil
+}
+func (c *busConnectionMock) WriteBlockData(reg uint8, b []byte) error {
+	c.record("WriteBlockData", reg)
+	return nil
+}
@@ -0,0 +1,204 @@
+package raildevices
+
+// A blinking lamp is a rail device used for warning lights with a single output, the blinking is done in software.
+// A crossing flasher is a rail device used for level crossings with two outputs, which are switched on alternating.
+// Both devices are driven by the cyclic call of "Tick()", so no sleep is needed and the cycle is not blocked.
+// The on and off periods are a multiple of the cycle time at least, e.g. 10ms.
+
+import (
+	"time"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+// Blinking contains the periods for on and off (or first and second output) of a blinking device
+type Blinking struct {
+	On  time.Duration
+	Off time.Duration
+}
+
+// blinker is the phase of a blinking device, the next change is scheduled at the first tick after switch on
+type blinker struct {
+	periods Blinking
+	phaseOn bool
+	next    time.Time
+}
+
+// BlinkingLampDevice is describes a blinking lamp
+type BlinkingLampDevice struct {
+	*CommonOutputDevice
+	output *boardpin.Output
+	blinker
+}
+
+// CrossingFlasherDevice is describes a flasher with two alternating lamps
+type CrossingFlasherDevice struct {
+	*CommonOutputDevice
+	outputFirst  *boardpin.Output
+	outputSecond *boardpin.Output
+	blinker
+}
+
+// NewBlinkingLamp creates an instance of a blinking lamp
+func NewBlinkingLamp(co *CommonOutputDevice, output *boardpin.Output, periods Blinking) (bl *BlinkingLampDevice) {
+	bl = &BlinkingLampDevice{
+		CommonOutputDevice: co,
+		output:             output,
+		blinker:            blinker{periods: periods},
+	}
+	return
+}
+
+// SwitchOn will switch on the lamp, the blinking is done by "Tick()"
+func (l *BlinkingLampDevice) SwitchOn() (err error) {
+	if err = l.IsDefective(); err != nil {
+		return
+	}
+	if err = l.output.WriteValue(1); err != nil {
+		return
+	}
+	l.startGuarded(l.CommonOutputDevice)
+	l.SetState(true)
+	return
+}
+
+// SwitchOff will switch off the lamp and stop blinking
+func (l *BlinkingLampDevice) SwitchOff() (err error) {
+	if err = l.output.WriteValue(0); err != nil {
+		return
+	}
+	l.SetState(false)
+	return
+}
+
+// Tick toggles the lamp, when the period of the current phase is elapsed
+func (l *BlinkingLampDevice) Tick(now time.Time) (err error) {
+	changed, phaseOn := l.tickWhileOn(l.CommonOutputDevice, now)
+	if !changed {
+		return
+	}
+	var value uint8
+	if phaseOn {
+		value = 1
+	}
+	return l.output.WriteValue(value)
+}
+
+// MakeDefective causes the lamp in an simulated defective state
+func (l *BlinkingLampDevice) MakeDefective() (err error) {
+	return l.MakeDefectiveCommon(l.SwitchOff)
+}
+
+// NewCrossingFlasher creates an instance of a flasher with two lamps
+func NewCrossingFlasher(co *CommonOutputDevice, outputFirst *boardpin.Output, outputSecond *boardpin.Output,
+	periods Blinking) (cf *CrossingFlasherDevice) {
+	cf = &CrossingFlasherDevice{
+		CommonOutputDevice: co,
+		outputFirst:        outputFirst,
+		outputSecond:       outputSecond,
+		blinker:            blinker{periods: periods},
+	}
+	return
+}
+
+// SwitchOn will switch on the first lamp, the alternating is done by "Tick()"
+func (f *CrossingFlasherDevice) SwitchOn() (err error) {
+	if err = f.IsDefective(); err != nil {
+		return
+	}
+	f.startGuarded(f.CommonOutputDevice)
+	if err = f.writeLamps(true); err != nil {
+		return
+	}
+	f.SetState(true)
+	return
+}
+
+// SwitchOff will switch off both lamps and stop flashing
+func (f *CrossingFlasherDevice) SwitchOff() (err error) {
+	if err = f.outputFirst.WriteValue(0); err != nil {
+		return
+	}
+	if err = f.outputSecond.WriteValue(0); err != nil {
+		return
+	}
+	f.SetState(false)
+	return
+}
+
+// Tick alternates the lamps, when the period of the current phase is elapsed
+func (f *CrossingFlasherDevice) Tick(now time.Time) (err error) {
+	changed, phaseOn := f.tickWhileOn(f.CommonOutputDevice, now)
+	if !changed {
+		return
+	}
+	return f.writeLamps(phaseOn)
+}
+
+// MakeDefective causes the flasher in an simulated defective state
+func (f *CrossingFlasherDevice) MakeDefective() (err error) {
+	return f.MakeDefectiveCommon(f.SwitchOff)
+}
+
+// writeLamps switches off the lamp of the last phase before switching on the lamp of the current phase
+func (f *CrossingFlasherDevice) writeLamps(phaseOn bool) (err error) {
+	lampOn, lampOff := f.outputFirst, f.outputSecond
+	if !phaseOn {
+		lampOn, lampOff = f.outputSecond, f.outputFirst
+	}
+	if err = lampOff.WriteValue(0); err != nil {
+		return
+	}
+	return lampOn.WriteValue(1)
+}
+
+// startGuarded is "start()" under the lock of the device
+func (b *blinker) startGuarded(o *CommonOutputDevice) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	b.start()
+}
+
+// tickWhileOn is "tick()" under the lock of the device, while the device is on
+// returns true and the current phase, when the phase was changed
+func (b *blinker) tickWhileOn(o *CommonOutputDevice, now time.Time) (changed bool, phaseOn bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.state {
+		changed = b.tick(now)
+	}
+	return changed, b.phaseOn
+}
+
+// start begins with the "on" phase, the end of the phase is scheduled by the next tick
+func (b *blinker) start() {
+	b.phaseOn = true
+	b.next = time.Time{}
+}
+
+// tick returns true, when the phase was changed
+func (b *blinker) tick(now time.Time) bool {
+	if b.next.IsZero() {
+		b.next = now.Add(b.period())
+		return false
+	}
+	if now.Before(b.next) {
+		return false
+	}
+	b.phaseOn = !b.phaseOn
+	b.next = b.next.Add(b.period())
+	// a blocked cycle should not lead to fast blinking afterwards
+	if b.next.Before(now) {
+		b.next = now.Add(b.period())
+	}
+	return true
+}
+
+func (b *blinker) period() time.Duration {
+	if b.phaseOn {
+		return b.periods.On
+	}
+	return b.periods.Off
+}
@@ -0,0 +1,138 @@
+package raildevices
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlinkingLampNew(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("blink dev", Timing{})
+	output := NewOutputMock(&WriteMock{})
+	periods := Blinking{On: 300 * time.Millisecond, Off: 700 * time.Millisecond}
+	// act
+	lamp := NewBlinkingLamp(co, output, periods)
+	// assert
+	require.NotNil(lamp)
+	assert.Equal(co, lamp.CommonOutputDevice)
+	assert.Equal(output, lamp.output)
+	assert.Equal(periods, lamp.periods)
+}
+
+func TestBlinkingLampTick(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("blink dev", Timing{})
+	wm := WriteMock{}
+	lamp := NewBlinkingLamp(co, NewOutputMock(&wm), Blinking{On: 300 * time.Millisecond, Off: 700 * time.Millisecond})
+	start := time.Now()
+	ticks := []time.Duration{0, 290, 300, 990, 1000, 1300}
+	// act
+	errOn := lamp.SwitchOn()
+	for _, tick := range ticks {
+		require.Nil(lamp.Tick(start.Add(tick * time.Millisecond)))
+	}
+	// assert
+	require.Nil(errOn)
+	assert.Equal([]uint8{1, 0, 1, 0}, wm.values[:wm.callCounter])
+	assert.True(lamp.IsOn())
+}
+
+func TestBlinkingLampTickAfterBlockedCycle(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	co := NewCommonOutput("blink dev", Timing{})
+	wm := WriteMock{}
+	lamp := NewBlinkingLamp(co, NewOutputMock(&wm), Blinking{On: 100 * time.Millisecond, Off: 100 * time.Millisecond})
+	start := time.Now()
+	// act
+	lamp.SwitchOn()
+	lamp.Tick(start)
+	lamp.Tick(start.Add(time.Second))
+	lamp.Tick(start.Add(time.Second + 10*time.Millisecond))
+	lamp.Tick(start.Add(time.Second + 100*time.Millisecond))
+	// assert
+	assert.Equal([]uint8{1, 0, 1}, wm.values[:wm.callCounter])
+}
+
+func TestBlinkingLampSwitchOffStopsBlinking(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("blink dev", Timing{})
+	wm := WriteMock{}
+	lamp := NewBlinkingLamp(co, NewOutputMock(&wm), Blinking{On: 10 * time.Millisecond, Off: 10 * time.Millisecond})
+	start := time.Now()
+	lamp.SwitchOn()
+	lamp.Tick(start)
+	// act
+	err := lamp.SwitchOff()
+	lamp.Tick(start.Add(time.Second))
+	// assert
+	require.Nil(err)
+	assert.Equal([]uint8{1, 0}, wm.values[:wm.callCounter])
+	assert.False(lamp.IsOn())
+}
+
+func TestBlinkingLampSwitchOnWhenErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("blink dev", Timing{})
+	expErr := errors.New("an error")
+	lamp := NewBlinkingLamp(co, NewOutputMock(&WriteMock{simError: expErr}), Blinking{})
+	// act
+	err := lamp.SwitchOn()
+	// assert
+	require.NotNil(err)
+	assert.Equal(expErr, err)
+	assert.False(lamp.IsOn())
+}
+
+func TestCrossingFlasherTick(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("flasher dev", Timing{})
+	var writes []lampWrite
+	outputs := newLampOutputs(2, &writes)
+	flasher := NewCrossingFlasher(co, outputs[0], outputs[1], Blinking{On: 500 * time.Millisecond, Off: 500 * time.Millisecond})
+	start := time.Now()
+	// act
+	errOn := flasher.SwitchOn()
+	for _, tick := range []time.Duration{0, 490, 500, 1000} {
+		require.Nil(flasher.Tick(start.Add(tick * time.Millisecond)))
+	}
+	errOff := flasher.SwitchOff()
+	// assert
+	require.Nil(errOn)
+	require.Nil(errOff)
+	// always off before on
+	assert.Equal([]lampWrite{{1, 0}, {0, 1}, {0, 0}, {1, 1}, {1, 0}, {0, 1}, {0, 0}, {1, 0}}, writes)
+	assert.False(flasher.IsOn())
+}
+
+func TestCrossingFlasherSwitchOnWhenDefectiveGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("flasher dev", Timing{})
+	var writes []lampWrite
+	outputs := newLampOutputs(2, &writes)
+	flasher := NewCrossingFlasher(co, outputs[0], outputs[1], Blinking{})
+	flasher.MakeDefective()
+	writes = nil
+	// act
+	err := flasher.SwitchOn()
+	// assert
+	require.NotNil(err)
+	assert.Empty(writes)
+	assert.False(flasher.IsOn())
+}
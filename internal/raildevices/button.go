@@ -4,6 +4,7 @@ package raildevices
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/gen2thomas/gobrail/internal/boardpin"
 )
@@ -14,6 +15,7 @@ type ButtonDevice struct {
 	state          bool
 	oldState       map[string]bool
 	input          *boardpin.Input
+	mutex          *sync.Mutex
 }
 
 // NewButton creates an instance of a Button
@@ -22,12 +24,16 @@ func NewButton(input *boardpin.Input, railDeviceName string) (b *ButtonDevice) {
 		railDeviceName: railDeviceName,
 		oldState:       make(map[string]bool),
 		input:          input,
+		mutex:          &sync.Mutex{},
 	}
 	return
 }
 
 // StateChanged states true when Button status was changed
 func (b *ButtonDevice) StateChanged(visitor string) (hasChanged bool, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
 	var value uint8
 	if value, err = b.input.ReadValue(); err != nil {
 		err = fmt.Errorf("Can't read value from '%s', %w", b.railDeviceName, err)
@@ -44,6 +50,9 @@ func (b *ButtonDevice) StateChanged(visitor string) (hasChanged bool, err error)
 
 // IsOn gets the state of the button
 func (b *ButtonDevice) IsOn() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
 	return b.state
 }
 
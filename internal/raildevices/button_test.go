@@ -3,10 +3,13 @@ package raildevices
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
 )
 
 func TestButtonNew(t *testing.T) {
@@ -75,3 +78,24 @@ func TestButtonStateChangedWhenReadErrorGetsError(t *testing.T) {
 	assert.Contains(err.Error(), "Can't read value from")
 	assert.Equal(expectedError, errors.Unwrap(err))
 }
+
+func TestButtonConcurrentVisitors(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	input := &boardpin.Input{ReadValue: func() (uint8, error) { return 1, nil }}
+	button := NewButton(input, "Button")
+	var wg sync.WaitGroup
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(visitor string) {
+			defer wg.Done()
+			button.StateChanged(visitor)
+			button.IsOn()
+		}(fmt.Sprintf("v%d", i))
+	}
+	wg.Wait()
+	// assert
+	assert.True(button.IsOn())
+	assert.Equal(10, len(button.oldState))
+}
@@ -1,9 +1,13 @@
 package raildevices
 
 // A common output is a rail device used for other output rail devices to minimize implementation and test effort
+// Timed actions (e.g. the end of a coil pulse) are not done by sleeping, but scheduled as steps of a sequence and
+// executed by the cyclic call of "Tick()" when the delay is elapsed, so the cycle is never blocked by a device.
+// A new sequence is started after all steps of the previous sequence are done, so the order of switching is kept.
 
 import (
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -14,6 +18,16 @@ type CommonOutputDevice struct {
 	oldState       map[string]bool
 	state          bool
 	defectiveState bool
+	mutex          *sync.Mutex
+	// pending steps of sequences, the deadline of the first step is set at the first tick
+	pending  []step
+	deadline time.Time
+}
+
+// step is a part of a sequence, the action is executed after the delay since the previous step
+type step struct {
+	delay  time.Duration
+	action func() (err error)
 }
 
 // NewCommonOutput creates an instance of a rail device for usage with outputs
@@ -22,12 +36,16 @@ func NewCommonOutput(railDeviceName string, timing Timing) (co *CommonOutputDevi
 		railDeviceName: railDeviceName,
 		timing:         timing,
 		oldState:       make(map[string]bool),
+		mutex:          &sync.Mutex{},
 	}
 	return
 }
 
 // StateChanged states true when Common output device status was changed since last visit
 func (o *CommonOutputDevice) StateChanged(visitor string) (hasChanged bool, err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
 	oldState, known := o.oldState[visitor]
 	if o.state != oldState || !known {
 		o.oldState[visitor] = o.state
@@ -38,11 +56,17 @@ func (o *CommonOutputDevice) StateChanged(visitor string) (hasChanged bool, err
 
 // IsOn states true when Common output device is on
 func (o *CommonOutputDevice) IsOn() bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
 	return o.state
 }
 
 // IsDefective states true when Common output device is defective
 func (o *CommonOutputDevice) IsDefective() (err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
 	if o.defectiveState {
 		err = fmt.Errorf("The '%s' is defective, please repair before switch on", o.railDeviceName)
 	}
@@ -55,13 +79,27 @@ func (o *CommonOutputDevice) MakeDefectiveCommon(offFunc func() (err error)) (er
 		err = fmt.Errorf("Can't switch off before make defective, %w", err)
 		return
 	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
 	o.defectiveState = true
 	return
 }
 
+// setDefective sets or resets the defective state, e.g. for a detected defect
+func (o *CommonOutputDevice) setDefective(defective bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.defectiveState = defective
+}
+
 // Repair will fix the simulated defective state
 func (o *CommonOutputDevice) Repair() (err error) {
-	if o.IsOn() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.state {
 		return fmt.Errorf("The '%s' can be only repaired when off", o.railDeviceName)
 	}
 	o.defectiveState = false
@@ -73,17 +111,98 @@ func (o *CommonOutputDevice) RailDeviceName() string {
 	return o.railDeviceName
 }
 
-// TimingForStart execute sleep with start time
-func (o *CommonOutputDevice) TimingForStart() {
-	time.Sleep(o.timing.Starting)
+// Tick executes all pending steps, which delay is elapsed
+// a failed step is kept and repeated at the next tick, e.g. to ensure a coil is switched off
+// calls of "Tick()", "CompletePending()" and the switch functions are serialized by the caller (rail devices API)
+func (o *CommonOutputDevice) Tick(now time.Time) (err error) {
+	for action := o.dueStep(now); action != nil; action = o.dueStep(now) {
+		if err = action(); err != nil {
+			return
+		}
+		o.stepDone()
+	}
+	return
+}
+
+// IsBusy states true, when steps of a sequence are pending
+func (o *CommonOutputDevice) IsBusy() bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return len(o.pending) > 0
+}
+
+// CompletePending executes all pending steps immediately without delay, e.g. before apply the safe state
+func (o *CommonOutputDevice) CompletePending() (err error) {
+	for action := o.firstStep(); action != nil; action = o.firstStep() {
+		if err = action(); err != nil {
+			return
+		}
+		o.stepDone()
+	}
+	return
+}
+
+// sequence executes the steps without delay immediately and schedules the remaining steps for "Tick()"
+// when the device is busy, all steps are scheduled after the pending steps
+// an error of an immediate step drops the remaining steps of this sequence
+func (o *CommonOutputDevice) sequence(steps ...step) (err error) {
+	for !o.IsBusy() && len(steps) > 0 && steps[0].delay == 0 {
+		if err = steps[0].action(); err != nil {
+			return
+		}
+		steps = steps[1:]
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.pending = append(o.pending, steps...)
+	return
+}
+
+// dueStep gets the action of the first pending step, when the delay is elapsed, otherwise nil
+// the actions are executed without lock, because they change the state
+func (o *CommonOutputDevice) dueStep(now time.Time) (action func() (err error)) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if len(o.pending) == 0 {
+		return nil
+	}
+	if o.deadline.IsZero() {
+		o.deadline = now.Add(o.pending[0].delay)
+	}
+	if now.Before(o.deadline) {
+		return nil
+	}
+	return o.pending[0].action
+}
+
+// firstStep gets the action of the first pending step regardless of the delay, nil when nothing is pending
+func (o *CommonOutputDevice) firstStep() (action func() (err error)) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if len(o.pending) == 0 {
+		return nil
+	}
+	return o.pending[0].action
 }
 
-// TimingForStop execute sleep with stop-time
-func (o *CommonOutputDevice) TimingForStop() {
-	time.Sleep(o.timing.Stopping)
+// stepDone drops the first pending step after successful execution, the deadline of the next step is set by the
+// next tick
+func (o *CommonOutputDevice) stepDone() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.pending = o.pending[1:]
+	o.deadline = time.Time{}
 }
 
 // SetState sets the new state
 func (o *CommonOutputDevice) SetState(newState bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
 	o.state = newState
 }
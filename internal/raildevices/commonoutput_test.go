@@ -1,7 +1,10 @@
 package raildevices
 
 import (
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -114,3 +117,98 @@ func TestCommonOutputMakeDefectiveWillSwitchOff(t *testing.T) {
 	assert.Equal(false, isOn)
 	assert.Equal(true, stateChanged)
 }
+
+func TestCommonOutputConcurrentUse(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	co := NewCommonOutput("CommonOutput", Timing{})
+	var wg sync.WaitGroup
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(visitor string) {
+			defer wg.Done()
+			co.SetState(true)
+			co.StateChanged(visitor)
+			co.IsDefective()
+			co.IsOn()
+		}(fmt.Sprintf("v%d", i))
+	}
+	wg.Wait()
+	// assert
+	assert.True(co.IsOn())
+	assert.Equal(10, len(co.oldState))
+}
+
+func TestCommonOutputConcurrentSequenceAndTick(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	co := NewCommonOutput("CommonOutput", Timing{})
+	var wg sync.WaitGroup
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			co.sequence(step{delay: time.Millisecond, action: func() error { co.SetState(true); return nil }})
+		}()
+		go func() {
+			defer wg.Done()
+			co.Tick(time.Now())
+			co.IsBusy()
+		}()
+	}
+	wg.Wait()
+	co.CompletePending()
+	// assert
+	assert.False(co.IsBusy())
+	assert.True(co.IsOn())
+}
+
+func TestCommonOutputTickRepeatsFailedStep(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("CommonOutput", Timing{})
+	var calls int
+	simErr := fmt.Errorf("an error")
+	require.Nil(co.sequence(step{delay: time.Second, action: func() error {
+		calls++
+		if calls == 1 {
+			return simErr
+		}
+		return nil
+	}}))
+	start := time.Now()
+	co.Tick(start)
+	// act
+	errFirst := co.Tick(start.Add(time.Second))
+	busyAfterError := co.IsBusy()
+	errSecond := co.Tick(start.Add(time.Second + 10*time.Millisecond))
+	// assert
+	assert.Equal(simErr, errFirst)
+	assert.True(busyAfterError)
+	assert.Nil(errSecond)
+	assert.Equal(2, calls)
+	assert.False(co.IsBusy())
+}
+
+func TestCommonOutputCompletePending(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("CommonOutput", Timing{})
+	var done []int
+	require.Nil(co.sequence(
+		step{action: func() error { done = append(done, 1); return nil }},
+		step{delay: time.Hour, action: func() error { done = append(done, 2); return nil }},
+		step{delay: time.Hour, action: func() error { done = append(done, 3); return nil }}))
+	doneBefore := len(done)
+	// act
+	err := co.CompletePending()
+	// assert
+	require.Nil(err)
+	assert.Equal(1, doneBefore)
+	assert.Equal([]int{1, 2, 3}, done)
+	assert.False(co.IsBusy())
+}
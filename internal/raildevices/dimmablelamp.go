@@ -0,0 +1,158 @@
+package raildevices
+
+// A dimmable lamp is a rail device used for street and building lighting with an analog output (e.g. PWM).
+// The lamp is faded in and out, the start and stop time of the timing is the duration for the full range of
+// brightness. The fading is driven by the cyclic call of "Tick()", so no sleep is needed and the cycle is not blocked.
+// A change of the direction while fading continues from the current brightness with the same rate.
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+const maxBrightness = 255
+
+// ramp is a gradual change of a value, the start is scheduled at the first tick after the change was requested
+type ramp struct {
+	value    uint8
+	from     uint8
+	to       uint8
+	duration time.Duration
+	start    time.Time
+	active   bool
+}
+
+// DimmableLampDevice is describes a lamp with fading
+type DimmableLampDevice struct {
+	*CommonOutputDevice
+	output *boardpin.Output
+	ramp
+}
+
+// NewDimmableLamp creates an instance of a dimmable lamp, the output needs to be an analog one
+func NewDimmableLamp(co *CommonOutputDevice, output *boardpin.Output) (dl *DimmableLampDevice) {
+	dl = &DimmableLampDevice{
+		CommonOutputDevice: co,
+		output:             output,
+	}
+	return
+}
+
+// SwitchOn will start to fade in the lamp, without start time the lamp is switched on immediately
+func (l *DimmableLampDevice) SwitchOn() (err error) {
+	if err = l.IsDefective(); err != nil {
+		return
+	}
+	if err = l.fadeTo(maxBrightness, l.timing.Starting); err != nil {
+		return
+	}
+	l.SetState(true)
+	return
+}
+
+// SwitchOff will start to fade out the lamp, without stop time the lamp is switched off immediately
+func (l *DimmableLampDevice) SwitchOff() (err error) {
+	if err = l.fadeTo(0, l.timing.Stopping); err != nil {
+		return
+	}
+	l.SetState(false)
+	return
+}
+
+// Tick writes the brightness, while the lamp is fading
+func (l *DimmableLampDevice) Tick(now time.Time) (err error) {
+	changed, value := l.tickGuarded(l.mutex, now)
+	if !changed {
+		return
+	}
+	return l.output.WriteValue(value)
+}
+
+// Brightness gets the current brightness 0..255
+func (l *DimmableLampDevice) Brightness() uint8 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.value
+}
+
+// MakeDefective causes the lamp in an simulated defective state, the lamp is switched off without fading
+func (l *DimmableLampDevice) MakeDefective() (err error) {
+	return l.MakeDefectiveCommon(func() (err error) {
+		if err = l.fadeTo(0, 0); err != nil {
+			return
+		}
+		l.SetState(false)
+		return
+	})
+}
+
+// fadeTo starts fading to the given brightness, without duration the brightness is written immediately
+func (l *DimmableLampDevice) fadeTo(brightness uint8, fullRange time.Duration) (err error) {
+	if !l.moveToGuarded(l.mutex, brightness, fullRange) {
+		return
+	}
+	return l.output.WriteValue(brightness)
+}
+
+// moveToGuarded is "moveTo()" under the given lock of the device
+func (r *ramp) moveToGuarded(mutex *sync.Mutex, to uint8, fullRange time.Duration) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return r.moveTo(to, fullRange)
+}
+
+// moveTo starts the change to the given value, the duration is scaled by the distance to the current value
+// returns true, when the value was changed immediately, because no duration is given
+func (r *ramp) moveTo(to uint8, fullRange time.Duration) bool {
+	r.from = r.value
+	r.to = to
+	r.duration = fullRange * time.Duration(absDiff(r.value, to)) / maxBrightness
+	r.start = time.Time{}
+	r.active = r.value != to
+	if r.active && r.duration == 0 {
+		r.value = to
+		r.active = false
+		return true
+	}
+	return false
+}
+
+// tickGuarded is "tick()" under the given lock of the device, returns true and the value to write, when changed
+func (r *ramp) tickGuarded(mutex *sync.Mutex, now time.Time) (changed bool, value uint8) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	changed = r.tick(now)
+	return changed, r.value
+}
+
+// tick returns true, when the value was changed
+func (r *ramp) tick(now time.Time) bool {
+	if !r.active {
+		return false
+	}
+	if r.start.IsZero() {
+		r.start = now
+	}
+	oldValue := r.value
+	elapsed := now.Sub(r.start)
+	if elapsed >= r.duration {
+		r.value = r.to
+		r.active = false
+	} else {
+		delta := time.Duration(int(r.to)-int(r.from)) * elapsed / r.duration
+		r.value = uint8(int(r.from) + int(delta))
+	}
+	return r.value != oldValue
+}
+
+func absDiff(a uint8, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,124 @@
+package raildevices
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDimmableLampNew(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("dim dev", Timing{})
+	output := NewOutputMock(&WriteMock{})
+	// act
+	lamp := NewDimmableLamp(co, output)
+	// assert
+	require.NotNil(lamp)
+	assert.Equal(co, lamp.CommonOutputDevice)
+	assert.Equal(output, lamp.output)
+	assert.Equal(uint8(0), lamp.Brightness())
+}
+
+func TestDimmableLampFadeInAndOut(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("dim dev", Timing{Starting: time.Second, Stopping: 2 * time.Second})
+	var writes []lampWrite
+	lamp := NewDimmableLamp(co, newLampOutputs(1, &writes)[0])
+	start := time.Now()
+	// act
+	errOn := lamp.SwitchOn()
+	isOn := lamp.IsOn()
+	for _, tick := range []time.Duration{0, 500, 1000, 1500} {
+		require.Nil(lamp.Tick(start.Add(tick * time.Millisecond)))
+	}
+	errOff := lamp.SwitchOff()
+	for _, tick := range []time.Duration{2000, 3000, 4000} {
+		require.Nil(lamp.Tick(start.Add(tick * time.Millisecond)))
+	}
+	// assert
+	require.Nil(errOn)
+	require.Nil(errOff)
+	assert.True(isOn)
+	assert.False(lamp.IsOn())
+	assert.Equal([]lampWrite{{0, 127}, {0, 255}, {0, 128}, {0, 0}}, writes)
+}
+
+func TestDimmableLampChangeDirectionWhileFading(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	co := NewCommonOutput("dim dev", Timing{Starting: time.Second, Stopping: time.Second})
+	var writes []lampWrite
+	lamp := NewDimmableLamp(co, newLampOutputs(1, &writes)[0])
+	start := time.Now()
+	lamp.SwitchOn()
+	lamp.Tick(start)
+	lamp.Tick(start.Add(400 * time.Millisecond))
+	// act
+	lamp.SwitchOff()
+	lamp.Tick(start.Add(500 * time.Millisecond))
+	lamp.Tick(start.Add(600 * time.Millisecond))
+	lamp.Tick(start.Add(900 * time.Millisecond))
+	// assert
+	// the way back from 102 needs 0.4s
+	assert.Equal([]lampWrite{{0, 102}, {0, 77}, {0, 0}}, writes)
+	assert.Equal(uint8(0), lamp.Brightness())
+}
+
+func TestDimmableLampWithoutTimingSwitchesImmediately(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("dim dev", Timing{})
+	wm := WriteMock{}
+	lamp := NewDimmableLamp(co, NewOutputMock(&wm))
+	// act
+	errOn := lamp.SwitchOn()
+	errTick := lamp.Tick(time.Now())
+	errOff := lamp.SwitchOff()
+	// assert
+	require.Nil(errOn)
+	require.Nil(errTick)
+	require.Nil(errOff)
+	assert.Equal([]uint8{255, 0}, wm.values[:wm.callCounter])
+}
+
+func TestDimmableLampSwitchOnWhenDefectiveGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("dim dev", Timing{Starting: time.Second})
+	wm := WriteMock{}
+	lamp := NewDimmableLamp(co, NewOutputMock(&wm))
+	require.Nil(lamp.MakeDefective())
+	// act
+	err := lamp.SwitchOn()
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "defective")
+	assert.False(lamp.IsOn())
+	assert.Equal(0, wm.callCounter)
+}
+
+func TestDimmableLampTickWhenErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("dim dev", Timing{Starting: time.Second})
+	expErr := errors.New("an error")
+	lamp := NewDimmableLamp(co, NewOutputMock(&WriteMock{simError: expErr}))
+	start := time.Now()
+	require.Nil(lamp.SwitchOn())
+	lamp.Tick(start)
+	// act
+	err := lamp.Tick(start.Add(time.Second))
+	// assert
+	require.NotNil(err)
+	assert.Equal(expErr, err)
+}
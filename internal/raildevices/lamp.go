@@ -27,22 +27,24 @@ func (l *LampDevice) SwitchOn() (err error) {
 	if err = l.IsDefective(); err != nil {
 		return
 	}
-	l.TimingForStart()
-	if err = l.output.WriteValue(1); err != nil {
+	return l.sequence(step{delay: l.timing.Starting, action: func() (err error) {
+		if err = l.output.WriteValue(1); err != nil {
+			return
+		}
+		l.SetState(true)
 		return
-	}
-	l.SetState(true)
-	return
+	}})
 }
 
 // SwitchOff will switch off the lamp
 func (l *LampDevice) SwitchOff() (err error) {
-	l.TimingForStop()
-	if err = l.output.WriteValue(0); err != nil {
+	return l.sequence(step{delay: l.timing.Stopping, action: func() (err error) {
+		if err = l.output.WriteValue(0); err != nil {
+			return
+		}
+		l.SetState(false)
 		return
-	}
-	l.SetState(false)
-	return
+	}})
 }
 
 // MakeDefective causes the lamp in an simulated defective state
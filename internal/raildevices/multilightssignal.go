@@ -0,0 +1,113 @@
+package raildevices
+
+// A multi light signal is a rail device used for sign more than "pass" or "stop" with three or four lamps, e.g. the
+// German main signal with "Hp0" (stop), "Hp1" (pass), "Hp2" (pass slowly) and "Sh1" (shunting allowed).
+// Each aspect is a combination of lamps. On change of the aspect all lamps, which are not part of the new aspect, are
+// switched off before the lamps of the new aspect are switched on (break before make), so a wrong aspect is never
+// shown.
+// The signal can be used like a two light signal, "on" shows the aspect for "pass" and "off" the aspect for "stop".
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+// Aspects are the named combinations of lamps, one value for each output
+type Aspects map[string][]uint8
+
+// ThreeLightsAspects are the default aspects for the outputs red, green, yellow
+var ThreeLightsAspects = Aspects{"Hp0": {1, 0, 0}, "Hp1": {0, 1, 0}, "Hp2": {0, 1, 1}}
+
+// FourLightsAspects are the default aspects for the outputs red, green, yellow, white
+var FourLightsAspects = Aspects{"Hp0": {1, 0, 0, 0}, "Hp1": {0, 1, 0, 0}, "Hp2": {0, 1, 1, 0}, "Sh1": {1, 0, 0, 1}}
+
+// MultiLightsSignalDevice is describes a signal with three or four lights
+type MultiLightsSignalDevice struct {
+	*CommonOutputDevice
+	outputs   []*boardpin.Output
+	aspects   Aspects
+	aspectOn  string
+	aspectOff string
+	aspect    string
+}
+
+// NewMultiLightsSignal creates an instance of a light signal with the given aspects, the aspect for "on" and "off"
+// must be one of the aspects
+func NewMultiLightsSignal(co *CommonOutputDevice, outputs []*boardpin.Output, aspects Aspects, aspectOn string,
+	aspectOff string) (s *MultiLightsSignalDevice, err error) {
+	for aspect, values := range aspects {
+		if len(values) != len(outputs) {
+			return nil, fmt.Errorf("The aspect '%s' of '%s' needs %d values", aspect, co.RailDeviceName(), len(outputs))
+		}
+	}
+	for _, aspect := range []string{aspectOn, aspectOff} {
+		if _, ok := aspects[aspect]; !ok {
+			return nil, fmt.Errorf("The aspect '%s' is unknown for '%s'", aspect, co.RailDeviceName())
+		}
+	}
+	s = &MultiLightsSignalDevice{
+		CommonOutputDevice: co,
+		outputs:            outputs,
+		aspects:            aspects,
+		aspectOn:           aspectOn,
+		aspectOff:          aspectOff,
+	}
+	return
+}
+
+// SwitchOn will show the aspect for "pass"
+func (s *MultiLightsSignalDevice) SwitchOn() (err error) {
+	return s.sequence(step{delay: s.timing.Starting, action: func() error { return s.SetAspect(s.aspectOn) }})
+}
+
+// SwitchOff will show the aspect for "stop"
+func (s *MultiLightsSignalDevice) SwitchOff() (err error) {
+	return s.sequence(step{delay: s.timing.Stopping, action: func() error { return s.SetAspect(s.aspectOff) }})
+}
+
+// SetAspect will switch off all lamps, which are not part of the given aspect, and switch on the lamps afterwards
+// the state is "on" for all aspects except the aspect for "stop"
+func (s *MultiLightsSignalDevice) SetAspect(aspect string) (err error) {
+	values, ok := s.aspects[aspect]
+	if !ok {
+		return fmt.Errorf("The aspect '%s' is unknown for '%s'", aspect, s.RailDeviceName())
+	}
+	for i, output := range s.outputs {
+		if values[i] == 0 {
+			if err = output.WriteValue(0); err != nil {
+				return
+			}
+		}
+	}
+	for i, output := range s.outputs {
+		if values[i] != 0 {
+			if err = output.WriteValue(1); err != nil {
+				return
+			}
+		}
+	}
+	s.mutex.Lock()
+	s.aspect = aspect
+	s.mutex.Unlock()
+	s.SetState(aspect != s.aspectOff)
+	return
+}
+
+// Aspect gets the currently shown aspect, empty before the first switch
+func (s *MultiLightsSignalDevice) Aspect() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.aspect
+}
+
+// Aspects gets the sorted names of all aspects
+func (s *MultiLightsSignalDevice) Aspects() (aspects []string) {
+	for aspect := range s.aspects {
+		aspects = append(aspects, aspect)
+	}
+	sort.Strings(aspects)
+	return
+}
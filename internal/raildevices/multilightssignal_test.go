@@ -0,0 +1,145 @@
+package raildevices
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+type lampWrite struct {
+	lamp  int
+	value uint8
+}
+
+func newLampOutputs(count int, writes *[]lampWrite) (outputs []*boardpin.Output) {
+	for i := 0; i < count; i++ {
+		lamp := i
+		outputs = append(outputs, &boardpin.Output{WriteValue: func(value uint8) (err error) {
+			*writes = append(*writes, lampWrite{lamp: lamp, value: value})
+			return
+		}})
+	}
+	return
+}
+
+func TestMultiLightsSignalNew(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("mls dev", Timing{})
+	outputs := newLampOutputs(3, &[]lampWrite{})
+	// act
+	mls, err := NewMultiLightsSignal(co, outputs, ThreeLightsAspects, "Hp1", "Hp0")
+	// assert
+	require.Nil(err)
+	require.NotNil(mls)
+	assert.Equal(co, mls.CommonOutputDevice)
+	assert.Equal([]string{"Hp0", "Hp1", "Hp2"}, mls.Aspects())
+	assert.Equal("", mls.Aspect())
+}
+
+func TestMultiLightsSignalNewNotValidGetsError(t *testing.T) {
+	var newTests = map[string]struct {
+		aspects   Aspects
+		aspectOn  string
+		aspectOff string
+		wantErr   string
+	}{
+		"ValuesCount":       {aspects: FourLightsAspects, aspectOn: "Hp1", aspectOff: "Hp0", wantErr: "needs 3 values"},
+		"AspectOnUnknown":   {aspects: ThreeLightsAspects, aspectOn: "Sh1", aspectOff: "Hp0", wantErr: "'Sh1' is unknown"},
+		"AspectOffUnknown":  {aspects: ThreeLightsAspects, aspectOn: "Hp1", aspectOff: "Hp00", wantErr: "'Hp00' is unknown"},
+		"AspectOffNotGiven": {aspects: ThreeLightsAspects, aspectOn: "Hp1", wantErr: "'' is unknown"},
+	}
+	for name, nt := range newTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			co := NewCommonOutput("mls dev", Timing{})
+			// act
+			mls, err := NewMultiLightsSignal(co, newLampOutputs(3, &[]lampWrite{}), nt.aspects, nt.aspectOn, nt.aspectOff)
+			// assert
+			require.NotNil(err)
+			assert.Nil(mls)
+			assert.Contains(err.Error(), nt.wantErr)
+		})
+	}
+}
+
+func TestMultiLightsSignalSetAspectBreakBeforeMake(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("mls dev", Timing{})
+	var writes []lampWrite
+	mls, _ := NewMultiLightsSignal(co, newLampOutputs(4, &writes), FourLightsAspects, "Hp1", "Hp0")
+	// act
+	err := mls.SetAspect("Sh1")
+	// assert
+	require.Nil(err)
+	require.Equal(4, len(writes))
+	// green and yellow off before red and white on
+	assert.Equal([]lampWrite{{1, 0}, {2, 0}, {0, 1}, {3, 1}}, writes)
+	assert.Equal("Sh1", mls.Aspect())
+	assert.Equal(true, mls.IsOn())
+}
+
+func TestMultiLightsSignalSwitchOnOff(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("mls dev", Timing{})
+	var writes []lampWrite
+	mls, _ := NewMultiLightsSignal(co, newLampOutputs(3, &writes), ThreeLightsAspects, "Hp2", "Hp0")
+	// act
+	errOn := mls.SwitchOn()
+	aspectOn := mls.Aspect()
+	isOn := mls.IsOn()
+	errOff := mls.SwitchOff()
+	// assert
+	require.Nil(errOn)
+	require.Nil(errOff)
+	assert.Equal("Hp2", aspectOn)
+	assert.True(isOn)
+	assert.Equal("Hp0", mls.Aspect())
+	assert.False(mls.IsOn())
+	assert.Equal([]lampWrite{{0, 0}, {1, 1}, {2, 1}, {1, 0}, {2, 0}, {0, 1}}, writes)
+}
+
+func TestMultiLightsSignalSetAspectUnknownGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("mls dev", Timing{})
+	var writes []lampWrite
+	mls, _ := NewMultiLightsSignal(co, newLampOutputs(3, &writes), ThreeLightsAspects, "Hp1", "Hp0")
+	// act
+	err := mls.SetAspect("Sh1")
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "'Sh1' is unknown")
+	assert.Empty(writes)
+}
+
+func TestMultiLightsSignalSetAspectWhenErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("mls dev", Timing{})
+	expErr := errors.New("an error")
+	wmGreen := WriteMock{}
+	outputs := []*boardpin.Output{NewOutputMock(&WriteMock{simError: expErr}), NewOutputMock(&wmGreen),
+		NewOutputMock(&WriteMock{})}
+	mls, _ := NewMultiLightsSignal(co, outputs, ThreeLightsAspects, "Hp1", "Hp0")
+	// act
+	err := mls.SetAspect("Hp1")
+	// assert
+	require.NotNil(err)
+	assert.Equal(expErr, err)
+	assert.Equal(0, wmGreen.callCounter)
+	assert.Equal("", mls.Aspect())
+}
@@ -0,0 +1,79 @@
+package raildevices
+
+// A semaphore signal is a rail device used for sign "pass" or "stop" with a movable arm.
+// Like a turnout the arm is moved by two coils (pass, stop), which must not be permanent set to on, but only for a
+// time period of 0.25-1s. The arm keeps its position without power. The coil is switched off by "Tick()".
+// An optional auxiliary output is switched on together with "pass" and off before "stop", e.g. for a lamp of the
+// signal or the power of the stop section in front of the signal.
+
+import (
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+// SemaphoreSignalDevice is describes a semaphore signal with two coils
+type SemaphoreSignalDevice struct {
+	*CommonOutputDevice
+	outputPass *boardpin.Output
+	outputStop *boardpin.Output
+	outputAux  *boardpin.Output
+}
+
+// NewSemaphoreSignal creates an instance of a semaphore signal, the auxiliary output is optional (nil)
+func NewSemaphoreSignal(co *CommonOutputDevice, outputPass *boardpin.Output, outputStop *boardpin.Output,
+	outputAux *boardpin.Output) (s *SemaphoreSignalDevice) {
+	s = &SemaphoreSignalDevice{
+		CommonOutputDevice: co,
+		outputPass:         outputPass,
+		outputStop:         outputStop,
+		outputAux:          outputAux,
+	}
+	return
+}
+
+// SwitchOn will move the arm to "pass" and switch on the auxiliary output afterwards
+func (s *SemaphoreSignalDevice) SwitchOn() (err error) {
+	return s.sequence(
+		step{action: func() error { return s.outputPass.WriteValue(1) }},
+		step{delay: s.timing.Starting, action: func() error { return s.outputPass.WriteValue(0) }},
+		step{action: func() (err error) {
+			if err = s.writeAux(1); err != nil {
+				return
+			}
+			s.SetState(true)
+			return
+		}})
+}
+
+// SwitchOff will switch off the auxiliary output and move the arm to "stop" afterwards
+func (s *SemaphoreSignalDevice) SwitchOff() (err error) {
+	return s.sequence(
+		step{action: func() error { return s.writeAux(0) }},
+		step{action: func() error { return s.outputStop.WriteValue(1) }},
+		step{delay: s.timing.Stopping, action: func() (err error) {
+			if err = s.outputStop.WriteValue(0); err != nil {
+				return
+			}
+			s.SetState(false)
+			return
+		}})
+}
+
+// RestoreState takes over a stored state without pulsing the coils, only the auxiliary output is written
+func (s *SemaphoreSignalDevice) RestoreState(state bool) (err error) {
+	var value uint8
+	if state {
+		value = 1
+	}
+	if err = s.writeAux(value); err != nil {
+		return
+	}
+	s.SetState(state)
+	return
+}
+
+func (s *SemaphoreSignalDevice) writeAux(value uint8) (err error) {
+	if s.outputAux == nil {
+		return
+	}
+	return s.outputAux.WriteValue(value)
+}
@@ -0,0 +1,122 @@
+package raildevices
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemaphoreSignalNew(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("semaphore dev", Timing{})
+	output1 := NewOutputMock(&WriteMock{})
+	output2 := NewOutputMock(&WriteMock{})
+	// act
+	semaphore := NewSemaphoreSignal(co, output1, output2, nil)
+	// assert
+	require.NotNil(semaphore)
+	assert.Equal(co, semaphore.CommonOutputDevice)
+	assert.Equal(output1, semaphore.outputPass)
+	assert.Equal(output2, semaphore.outputStop)
+	assert.Nil(semaphore.outputAux)
+}
+
+func TestSemaphoreSignalSwitchOn(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("semaphore dev", Timing{})
+	wmPass := WriteMock{}
+	wmStop := WriteMock{}
+	wmAux := WriteMock{}
+	semaphore := NewSemaphoreSignal(co, NewOutputMock(&wmPass), NewOutputMock(&wmStop), NewOutputMock(&wmAux))
+	// act
+	err := semaphore.SwitchOn()
+	// assert
+	require.Nil(err)
+	require.Equal(2, wmPass.callCounter)
+	assert.Equal(0, wmStop.callCounter)
+	assert.Equal(uint8(1), wmPass.values[0])
+	assert.Equal(uint8(0), wmPass.values[1])
+	require.Equal(1, wmAux.callCounter)
+	assert.Equal(uint8(1), wmAux.values[0])
+	assert.Equal(true, semaphore.IsOn())
+}
+
+func TestSemaphoreSignalSwitchOff(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("semaphore dev", Timing{})
+	wmPass := WriteMock{}
+	wmStop := WriteMock{}
+	semaphore := NewSemaphoreSignal(co, NewOutputMock(&wmPass), NewOutputMock(&wmStop), nil)
+	co.SetState(true)
+	// act
+	err := semaphore.SwitchOff()
+	// assert
+	require.Nil(err)
+	require.Equal(2, wmStop.callCounter)
+	assert.Equal(0, wmPass.callCounter)
+	assert.Equal(uint8(1), wmStop.values[0])
+	assert.Equal(uint8(0), wmStop.values[1])
+	assert.Equal(false, semaphore.IsOn())
+}
+
+func TestSemaphoreSignalSwitchOffWhenAuxErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("semaphore dev", Timing{})
+	expErr := errors.New("an error")
+	wmStop := WriteMock{}
+	semaphore := NewSemaphoreSignal(co, NewOutputMock(&WriteMock{}), NewOutputMock(&wmStop),
+		NewOutputMock(&WriteMock{simError: expErr}))
+	co.SetState(true)
+	// act
+	err := semaphore.SwitchOff()
+	// assert
+	require.NotNil(err)
+	assert.Equal(expErr, err)
+	assert.Equal(0, wmStop.callCounter)
+	assert.Equal(true, semaphore.IsOn())
+}
+
+func TestSemaphoreSignalSwitchOnWhenErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("semaphore dev", Timing{})
+	expErr := errors.New("an error")
+	semaphore := NewSemaphoreSignal(co, NewOutputMock(&WriteMock{simError: expErr}), NewOutputMock(&WriteMock{}), nil)
+	// act
+	err := semaphore.SwitchOn()
+	// assert
+	require.NotNil(err)
+	assert.Equal(expErr, err)
+	assert.Equal(false, semaphore.IsOn())
+}
+
+func TestSemaphoreSignalRestoreState(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("semaphore dev", Timing{})
+	wmPass := WriteMock{}
+	wmStop := WriteMock{}
+	wmAux := WriteMock{}
+	semaphore := NewSemaphoreSignal(co, NewOutputMock(&wmPass), NewOutputMock(&wmStop), NewOutputMock(&wmAux))
+	// act
+	err := semaphore.RestoreState(true)
+	// assert
+	require.Nil(err)
+	assert.Equal(0, wmPass.callCounter)
+	assert.Equal(0, wmStop.callCounter)
+	require.Equal(1, wmAux.callCounter)
+	assert.Equal(uint8(1), wmAux.values[0])
+	assert.Equal(true, semaphore.IsOn())
+}
@@ -0,0 +1,164 @@
+package raildevices
+
+// A servo turnout is a turnout driven by a servo at an analog output (PWM) instead of two coils.
+// The positions for main and branch are calibrated by the values written to the output, the servo is moved
+// gradually by the cyclic call of "Tick()" with the travel speed. The state is changed, when the end position is
+// reached. At first switch the position of the servo is unknown, so the end position is written immediately.
+
+import (
+	"time"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+)
+
+// ServoPositions are the values of the analog output for the end positions of a servo turnout
+type ServoPositions struct {
+	Main   uint8
+	Branch uint8
+}
+
+// ServoTurnoutDevice is describes a turnout with a servo
+type ServoTurnoutDevice struct {
+	*CommonOutputDevice
+	output     *boardpin.Output
+	positions  ServoPositions
+	travelTime time.Duration
+	positioned bool
+	// the state is set, when the end position is reached
+	statePending bool
+	targetState  bool
+	ramp
+}
+
+// NewServoTurnout creates an instance of a servo turnout, the travel time is the duration for the full range 0..255
+func NewServoTurnout(co *CommonOutputDevice, output *boardpin.Output, positions ServoPositions,
+	travelTime time.Duration) (s *ServoTurnoutDevice) {
+	s = &ServoTurnoutDevice{
+		CommonOutputDevice: co,
+		output:             output,
+		positions:          positions,
+		travelTime:         travelTime,
+	}
+	return
+}
+
+// SwitchOn will start to move the servo to the branch position
+func (s *ServoTurnoutDevice) SwitchOn() (err error) {
+	return s.moveServo(s.positions.Branch, true)
+}
+
+// SwitchOff will start to move the servo to the main position
+func (s *ServoTurnoutDevice) SwitchOff() (err error) {
+	return s.moveServo(s.positions.Main, false)
+}
+
+// RestoreState takes over a stored state, the position is written without moving, because the servo is already there
+func (s *ServoTurnoutDevice) RestoreState(state bool) (err error) {
+	position := s.positions.Main
+	if state {
+		position = s.positions.Branch
+	}
+	return s.setPosition(position, state)
+}
+
+// Tick writes the position, while the servo is moving
+func (s *ServoTurnoutDevice) Tick(now time.Time) (err error) {
+	changed, value := s.tickGuarded(s.mutex, now)
+	if changed {
+		if err = s.output.WriteValue(value); err != nil {
+			return
+		}
+	}
+	s.finishMove()
+	return
+}
+
+// CompletePending moves the servo to the end position immediately, e.g. before apply the safe state
+func (s *ServoTurnoutDevice) CompletePending() (err error) {
+	position, state, moving := s.moveTarget()
+	if !moving {
+		return
+	}
+	return s.setPosition(position, state)
+}
+
+// IsBusy states true, while the servo is moving
+func (s *ServoTurnoutDevice) IsBusy() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.active
+}
+
+// Position gets the current position of the servo
+func (s *ServoTurnoutDevice) Position() uint8 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.value
+}
+
+func (s *ServoTurnoutDevice) moveServo(position uint8, state bool) (err error) {
+	if !s.isPositioned() {
+		return s.setPosition(position, state)
+	}
+	if s.startMove(position, state) {
+		if err = s.output.WriteValue(position); err != nil {
+			return
+		}
+	}
+	s.finishMove()
+	return
+}
+
+// setPosition writes the position immediately and stops a movement
+func (s *ServoTurnoutDevice) setPosition(position uint8, state bool) (err error) {
+	if err = s.output.WriteValue(position); err != nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.value = position
+	s.active = false
+	s.statePending = false
+	s.positioned = true
+	s.state = state
+	return
+}
+
+func (s *ServoTurnoutDevice) isPositioned() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.positioned
+}
+
+// startMove starts the movement to the position, returns true when the position was changed immediately
+func (s *ServoTurnoutDevice) startMove(position uint8, state bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.targetState = state
+	s.statePending = true
+	return s.moveTo(position, s.travelTime)
+}
+
+// moveTarget gets the end position and state of the current movement, moving is false when the servo stands still
+func (s *ServoTurnoutDevice) moveTarget() (position uint8, state bool, moving bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.to, s.targetState, s.active
+}
+
+// finishMove sets the state, when the end position is reached
+func (s *ServoTurnoutDevice) finishMove() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.active && s.statePending {
+		s.statePending = false
+		s.state = s.targetState
+	}
+}
@@ -0,0 +1,129 @@
+package raildevices
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServoTurnoutNew(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("servo dev", Timing{})
+	output := NewOutputMock(&WriteMock{})
+	positions := ServoPositions{Main: 20, Branch: 40}
+	// act
+	servo := NewServoTurnout(co, output, positions, time.Second)
+	// assert
+	require.NotNil(servo)
+	assert.Equal(co, servo.CommonOutputDevice)
+	assert.Equal(output, servo.output)
+	assert.Equal(positions, servo.positions)
+	assert.Equal(time.Second, servo.travelTime)
+}
+
+func TestServoTurnoutFirstSwitchIsImmediately(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("servo dev", Timing{})
+	wm := WriteMock{}
+	servo := NewServoTurnout(co, NewOutputMock(&wm), ServoPositions{Main: 20, Branch: 40}, time.Second)
+	// act
+	err := servo.SwitchOn()
+	// assert
+	require.Nil(err)
+	assert.Equal([]uint8{40}, wm.values[:wm.callCounter])
+	assert.True(servo.IsOn())
+	assert.False(servo.IsBusy())
+}
+
+func TestServoTurnoutMovesGradually(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("servo dev", Timing{})
+	var writes []lampWrite
+	// 51 positions per second
+	servo := NewServoTurnout(co, newLampOutputs(1, &writes)[0], ServoPositions{Main: 20, Branch: 40}, 5*time.Second)
+	require.Nil(servo.RestoreState(false))
+	start := time.Now()
+	// act
+	err := servo.SwitchOn()
+	isOnWhileMoving := servo.IsOn()
+	for _, tick := range []time.Duration{0, 100, 200, 300, 392} {
+		require.Nil(servo.Tick(start.Add(tick * time.Millisecond)))
+	}
+	isBusyBeforeEnd := servo.IsBusy()
+	require.Nil(servo.Tick(start.Add(400 * time.Millisecond)))
+	// assert
+	require.Nil(err)
+	assert.False(isOnWhileMoving)
+	assert.True(isBusyBeforeEnd)
+	assert.Equal([]lampWrite{{0, 20}, {0, 25}, {0, 30}, {0, 35}, {0, 39}, {0, 40}}, writes)
+	assert.True(servo.IsOn())
+	assert.False(servo.IsBusy())
+	assert.Equal(uint8(40), servo.Position())
+}
+
+func TestServoTurnoutCompletePending(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("servo dev", Timing{})
+	var writes []lampWrite
+	servo := NewServoTurnout(co, newLampOutputs(1, &writes)[0], ServoPositions{Main: 20, Branch: 40}, 5*time.Second)
+	require.Nil(servo.RestoreState(false))
+	start := time.Now()
+	require.Nil(servo.SwitchOn())
+	require.Nil(servo.Tick(start))
+	require.Nil(servo.Tick(start.Add(100 * time.Millisecond)))
+	// act
+	err := servo.CompletePending()
+	errNotMoving := servo.CompletePending()
+	// assert
+	require.Nil(err)
+	require.Nil(errNotMoving)
+	assert.Equal([]lampWrite{{0, 20}, {0, 25}, {0, 40}}, writes)
+	assert.True(servo.IsOn())
+	assert.False(servo.IsBusy())
+	assert.Equal(uint8(40), servo.Position())
+}
+
+func TestServoTurnoutSwitchToSamePosition(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("servo dev", Timing{})
+	wm := WriteMock{}
+	servo := NewServoTurnout(co, NewOutputMock(&wm), ServoPositions{Main: 20, Branch: 40}, time.Second)
+	require.Nil(servo.RestoreState(true))
+	co.SetState(false)
+	// act
+	err := servo.SwitchOn()
+	// assert
+	require.Nil(err)
+	assert.Equal(1, wm.callCounter)
+	assert.True(servo.IsOn())
+	assert.False(servo.IsBusy())
+}
+
+func TestServoTurnoutSwitchOnWhenErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("servo dev", Timing{})
+	expErr := errors.New("an error")
+	servo := NewServoTurnout(co, NewOutputMock(&WriteMock{simError: expErr}), ServoPositions{Main: 20, Branch: 40},
+		time.Second)
+	// act
+	err := servo.SwitchOn()
+	// assert
+	require.NotNil(err)
+	assert.Equal(expErr, err)
+	assert.False(servo.IsOn())
+}
@@ -5,6 +5,7 @@ package raildevices
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/gen2thomas/gobrail/internal/boardpin"
 )
@@ -16,6 +17,7 @@ type ToggleButtonDevice struct {
 	toggleState    bool
 	oldToggleState map[string]bool
 	input          *boardpin.Input
+	mutex          *sync.Mutex
 }
 
 // NewToggleButton creates an instance of a ToggleButton
@@ -24,12 +26,16 @@ func NewToggleButton(input *boardpin.Input, railDeviceName string) (ld *ToggleBu
 		railDeviceName: railDeviceName,
 		oldToggleState: make(map[string]bool),
 		input:          input,
+		mutex:          &sync.Mutex{},
 	}
 	return
 }
 
 // StateChanged states true when ToggleButton status was changed
 func (b *ToggleButtonDevice) StateChanged(visitor string) (hasChanged bool, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
 	var value uint8
 	if value, err = b.input.ReadValue(); err != nil {
 		err = fmt.Errorf("Can't read value from '%s', %w", b.railDeviceName, err)
@@ -52,6 +58,9 @@ func (b *ToggleButtonDevice) StateChanged(visitor string) (hasChanged bool, err
 
 // IsOn states true when toggle state is on
 func (b *ToggleButtonDevice) IsOn() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
 	return b.toggleState
 }
 
@@ -3,8 +3,12 @@ package raildevices
 // A turnout or railroad switch is a rail device used for changing the direction of a train to a diverging route.
 // The difference to a normal switch is the output must not be permanent set to on, but only for a time period of 0.25-1s
 // Second difference: A standard model turnout needs 2 physical outputs (left, right).
+// The coil is switched off by "Tick()" after the time period, the state is changed afterwards.
+// With optional feedback inputs (limit switches) the end position is verified after switching, when the expected
+// input is not active within the timeout, the turnout is marked as defective and can't be switched until repaired.
 
 import (
+	"fmt"
 	"github.com/gen2thomas/gobrail/internal/boardpin"
 	"time"
 )
@@ -16,6 +20,22 @@ type TurnoutDevice struct {
 	*CommonOutputDevice
 	outputBranch *boardpin.Output
 	outputMain   *boardpin.Output
+	feedback     TurnoutFeedback
+	verify       verification
+}
+
+// TurnoutFeedback are the optional inputs for the end positions, one input can be nil to verify only one position
+type TurnoutFeedback struct {
+	Branch  *boardpin.Input
+	Main    *boardpin.Input
+	Timeout time.Duration
+}
+
+// verification is the check of an end position, the deadline is set at the first tick after the switch is done
+type verification struct {
+	input    *boardpin.Input
+	position string
+	deadline time.Time
 }
 
 // NewTurnout creates an instance of a turnout
@@ -28,6 +48,14 @@ func NewTurnout(co *CommonOutputDevice, outputBranch *boardpin.Output, outputMai
 	return
 }
 
+// NewTurnoutWithFeedback creates an instance of a turnout with verification of the end positions
+func NewTurnoutWithFeedback(co *CommonOutputDevice, outputBranch *boardpin.Output, outputMain *boardpin.Output,
+	feedback TurnoutFeedback) (s *TurnoutDevice) {
+	s = NewTurnout(co, outputBranch, outputMain)
+	s.feedback = feedback
+	return
+}
+
 // SwitchOn will try to switch the turnout to diverging route
 // from the main route or the inner circle
 //
@@ -36,26 +64,109 @@ func NewTurnout(co *CommonOutputDevice, outputBranch *boardpin.Output, outputMai
 //               ||  --> IsOn = true  (train runs the inner circle)
 //              //
 func (s *TurnoutDevice) SwitchOn() (err error) {
-	if err = s.outputBranch.WriteValue(1); err != nil {
-		return
-	}
-	s.TimingForStart()
-	if err = s.outputBranch.WriteValue(0); err != nil {
+	if err = s.IsDefective(); err != nil {
 		return
 	}
-	s.SetState(true)
+	s.setVerification(verification{})
+	return s.sequence(
+		step{action: func() error { return s.outputBranch.WriteValue(1) }},
+		step{delay: s.timing.Starting, action: func() (err error) {
+			if err = s.outputBranch.WriteValue(0); err != nil {
+				return
+			}
+			s.SetState(true)
+			s.setVerification(verification{input: s.feedback.Branch, position: "branch"})
+			return
+		}})
+}
+
+// RestoreState takes over a stored state without pulsing the coils, the turnout keeps its position without power
+func (s *TurnoutDevice) RestoreState(state bool) (err error) {
+	s.SetState(state)
 	return
 }
 
 // SwitchOff will switch the turnout to main route
 func (s *TurnoutDevice) SwitchOff() (err error) {
-	if err = s.outputMain.WriteValue(1); err != nil {
+	if err = s.IsDefective(); err != nil {
 		return
 	}
-	s.TimingForStop()
-	if err = s.outputMain.WriteValue(0); err != nil {
+	s.setVerification(verification{})
+	return s.sequence(
+		step{action: func() error { return s.outputMain.WriteValue(1) }},
+		step{delay: s.timing.Stopping, action: func() (err error) {
+			if err = s.outputMain.WriteValue(0); err != nil {
+				return
+			}
+			s.SetState(false)
+			s.setVerification(verification{input: s.feedback.Main, position: "main"})
+			return
+		}})
+}
+
+// Tick executes the pending steps of switching and verifies the end position afterwards
+func (s *TurnoutDevice) Tick(now time.Time) (err error) {
+	if err = s.CommonOutputDevice.Tick(now); err != nil {
 		return
 	}
-	s.SetState(false)
+	return s.verifyPosition(now)
+}
+
+// IsBusy states true, while steps of switching are pending or the end position is not verified yet
+func (s *TurnoutDevice) IsBusy() bool {
+	if s.CommonOutputDevice.IsBusy() {
+		return true
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.verify.input != nil
+}
+
+// Repair will fix the defective state, the turnout can be repaired in both positions
+func (s *TurnoutDevice) Repair() (err error) {
+	s.setDefective(false)
 	return
 }
+
+// verifyPosition reads the feedback input until active or the timeout is elapsed, a failed read is repeated
+func (s *TurnoutDevice) verifyPosition(now time.Time) (err error) {
+	verify := s.pendingVerification(now)
+	if verify.input == nil {
+		return
+	}
+	var value uint8
+	if value, err = verify.input.ReadValue(); err != nil {
+		return fmt.Errorf("Can't read feedback of '%s', %w", s.RailDeviceName(), err)
+	}
+	if value > 0 {
+		s.setVerification(verification{})
+		return
+	}
+	if now.Before(verify.deadline) {
+		return
+	}
+	s.setVerification(verification{})
+	s.setDefective(true)
+	return fmt.Errorf("The '%s' has not reached the %s position within %s, marked as defective", s.RailDeviceName(),
+		verify.position, s.feedback.Timeout)
+}
+
+// pendingVerification gets the check of the end position, the deadline is set at the first call
+func (s *TurnoutDevice) pendingVerification(now time.Time) verification {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.verify.input != nil && s.verify.deadline.IsZero() {
+		s.verify.deadline = now.Add(s.feedback.Timeout)
+	}
+	return s.verify
+}
+
+// setVerification starts the check of an end position, an empty verification stops the check
+func (s *TurnoutDevice) setVerification(verify verification) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.verify = verify
+}
@@ -3,6 +3,7 @@ package raildevices
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -46,6 +47,22 @@ func TestTurnoutSwitchOn(t *testing.T) {
 	assert.Equal(true, turnout.IsOn())
 }
 
+func TestTurnoutRestoreState(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	co := NewCommonOutput("turnout dev", Timing{})
+	wmBranch := WriteMock{}
+	wmMain := WriteMock{}
+	turnout := NewTurnout(co, NewOutputMock(&wmBranch), NewOutputMock(&wmMain))
+	// act
+	err := turnout.RestoreState(true)
+	// assert
+	assert.Nil(err)
+	assert.Equal(0, wmBranch.callCounter)
+	assert.Equal(0, wmMain.callCounter)
+	assert.Equal(true, turnout.IsOn())
+}
+
 func TestTurnoutSwitchOnWhenErrorGetsError(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
@@ -104,3 +121,103 @@ func TestTurnoutSwitchOffWhenErrorGetsError(t *testing.T) {
 	assert.Equal(expErr, err)
 	assert.Equal(false, turnout.IsOn())
 }
+
+func TestTurnoutSwitchWithTimingIsNotBlockingAndKeepsOrder(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("turnout dev", Timing{Starting: 500 * time.Millisecond, Stopping: 500 * time.Millisecond})
+	var writes []lampWrite
+	outputs := newLampOutputs(2, &writes)
+	turnout := NewTurnout(co, outputs[0], outputs[1])
+	start := time.Now()
+	// act
+	errOn := turnout.SwitchOn()
+	errOff := turnout.SwitchOff()
+	writesAfterSwitch := len(writes)
+	require.Nil(turnout.Tick(start))
+	require.Nil(turnout.Tick(start.Add(499 * time.Millisecond)))
+	writesBeforeDeadline := len(writes)
+	require.Nil(turnout.Tick(start.Add(500 * time.Millisecond)))
+	isOnAfterFirstPulse := turnout.IsOn()
+	require.Nil(turnout.Tick(start.Add(1000 * time.Millisecond)))
+	// assert
+	require.Nil(errOn)
+	require.Nil(errOff)
+	assert.Equal(1, writesAfterSwitch)
+	assert.Equal(1, writesBeforeDeadline)
+	assert.True(isOnAfterFirstPulse)
+	// the main coil is energized after the branch coil is switched off
+	assert.Equal([]lampWrite{{0, 1}, {0, 0}, {1, 1}, {1, 0}}, writes)
+	assert.False(turnout.IsOn())
+	assert.False(turnout.IsBusy())
+}
+
+func TestTurnoutFeedbackVerified(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("turnout dev", Timing{Starting: 200 * time.Millisecond})
+	// inactive after switch, active at next tick
+	rmBranch := ReadMock{values: [5]uint8{0, 1}}
+	feedback := TurnoutFeedback{Branch: NewInputMock(&rmBranch), Timeout: time.Second}
+	turnout := NewTurnoutWithFeedback(co, NewOutputMock(&WriteMock{}), NewOutputMock(&WriteMock{}), feedback)
+	start := time.Now()
+	require.Nil(turnout.SwitchOn())
+	require.Nil(turnout.Tick(start))
+	require.Nil(turnout.Tick(start.Add(200 * time.Millisecond)))
+	isBusyWhileVerifying := turnout.IsBusy()
+	// act
+	err := turnout.Tick(start.Add(500 * time.Millisecond))
+	errLater := turnout.Tick(start.Add(2 * time.Second))
+	// assert
+	require.Nil(err)
+	require.Nil(errLater)
+	assert.True(isBusyWhileVerifying)
+	assert.False(turnout.IsBusy())
+	assert.Nil(turnout.IsDefective())
+	assert.True(turnout.IsOn())
+	assert.Equal(2, rmBranch.callCounter)
+}
+
+func TestTurnoutFeedbackTimeoutMakesDefective(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("turnout dev", Timing{})
+	wmMain := WriteMock{}
+	feedback := TurnoutFeedback{Main: NewInputMock(&ReadMock{}), Timeout: time.Second}
+	turnout := NewTurnoutWithFeedback(co, NewOutputMock(&WriteMock{}), NewOutputMock(&wmMain), feedback)
+	start := time.Now()
+	require.Nil(turnout.SwitchOff())
+	require.Nil(turnout.Tick(start))
+	require.Nil(turnout.Tick(start.Add(999 * time.Millisecond)))
+	// act
+	err := turnout.Tick(start.Add(time.Second))
+	errSwitch := turnout.SwitchOn()
+	errRepair := turnout.Repair()
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "'turnout dev' has not reached the main position within 1s")
+	require.NotNil(errSwitch)
+	assert.Contains(errSwitch.Error(), "defective")
+	assert.Equal(2, wmMain.callCounter)
+	assert.Nil(errRepair)
+	assert.Nil(turnout.IsDefective())
+}
+
+func TestTurnoutFeedbackReadErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	co := NewCommonOutput("turnout dev", Timing{})
+	feedback := TurnoutFeedback{Branch: NewInputMock(&ReadMock{simError: errors.New("read error")}), Timeout: time.Second}
+	turnout := NewTurnoutWithFeedback(co, NewOutputMock(&WriteMock{}), NewOutputMock(&WriteMock{}), feedback)
+	require.Nil(turnout.SwitchOn())
+	// act
+	err := turnout.Tick(time.Now())
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "Can't read feedback of 'turnout dev', read error")
+	assert.Nil(turnout.IsDefective())
+}
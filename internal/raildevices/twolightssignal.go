@@ -28,27 +28,29 @@ func NewTwoLightsSignal(co *CommonOutputDevice, outputPass *boardpin.Output, out
 // SwitchOn will try to switch off the "stop" light (e.g. red color)
 // and immediately switch on the "can pass" light (e.g. green color)
 func (s *TwoLightsSignalDevice) SwitchOn() (err error) {
-	s.TimingForStart()
-	if err = s.outputStop.WriteValue(0); err != nil {
+	return s.sequence(step{delay: s.timing.Starting, action: func() (err error) {
+		if err = s.outputStop.WriteValue(0); err != nil {
+			return
+		}
+		if err = s.outputPass.WriteValue(1); err != nil {
+			return
+		}
+		s.SetState(true)
 		return
-	}
-	if err = s.outputPass.WriteValue(1); err != nil {
-		return
-	}
-	s.SetState(true)
-	return
+	}})
 }
 
 // SwitchOff will try to switch off the "can pass" light (e.g. green color)
 // and immediately switch on the "stop" light (e.g. red color)
 func (s *TwoLightsSignalDevice) SwitchOff() (err error) {
-	s.TimingForStop()
-	if err = s.outputPass.WriteValue(0); err != nil {
+	return s.sequence(step{delay: s.timing.Stopping, action: func() (err error) {
+		if err = s.outputPass.WriteValue(0); err != nil {
+			return
+		}
+		if err = s.outputStop.WriteValue(1); err != nil {
+			return
+		}
+		s.SetState(false)
 		return
-	}
-	if err = s.outputStop.WriteValue(1); err != nil {
-		return
-	}
-	s.SetState(false)
-	return
+	}})
 }
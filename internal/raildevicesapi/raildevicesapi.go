@@ -3,10 +3,12 @@ package raildevicesapi
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gen2thomas/gobrail/internal/boardpin"
 	"github.com/gen2thomas/gobrail/internal/devicerecipe"
+	"github.com/gen2thomas/gobrail/internal/errwrap"
 	"github.com/gen2thomas/gobrail/internal/raildevices"
 )
 
@@ -25,6 +27,20 @@ type Outputer interface {
 	SwitchOff() (err error)
 }
 
+// Aspecter is an interface for output devices with more than two states, e.g. signals with named aspects
+type Aspecter interface {
+	RailDeviceName() string
+	Aspects() []string
+	Aspect() string
+	SetAspect(aspect string) (err error)
+}
+
+// Ticker is an interface for output devices, which change their outputs over time without a change of the input,
+// e.g. blinking lamps, the function is called in each cycle and must not block
+type Ticker interface {
+	Tick(now time.Time) (err error)
+}
+
 // Runner is an interface for devices which can call cyclic
 type Runner interface {
 	Inputer
@@ -35,20 +51,36 @@ type Runner interface {
 type BoardsIOAPIer interface {
 	GetInputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Input, err error)
 	GetOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error)
+	GetAnalogOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error)
+	GetServoOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error)
+	GetStoragePin(boardID string, boardPinNr uint8) (boardPin *boardpin.Storage, err error)
+	ReleasePin(boardID string, boardPinNr uint8) (err error)
 }
 
+const (
+	defaultBlinkPeriod     = 500 * time.Millisecond
+	defaultFeedbackTimeout = time.Second
+	// positions per second
+	defaultServoSpeed = 50
+)
+
 type connection struct {
 	name    string
 	inverse bool
 }
 
-// RailDeviceAPI describes the API
+// RailDeviceAPI describes the API, it is safe for concurrent use
 type RailDeviceAPI struct {
 	boardsIOAPI    BoardsIOAPIer
 	devices        map[string]struct{}
 	runableDevices map[string]*runableDevice
 	inputDevices   map[string]Inputer
 	connections    map[string]connection
+	recipes        map[string]devicerecipe.Ingredients
+	safeStates     map[string]*safeOutputs
+	// keys of all devices in order of adding
+	order []string
+	mutex sync.Mutex
 }
 
 // NewRailDevicesAPI creates a new instance of rail device API
@@ -59,17 +91,26 @@ func NewRailDevicesAPI(boardsIOAPI BoardsIOAPIer) *RailDeviceAPI {
 		runableDevices: make(map[string]*runableDevice),
 		inputDevices:   make(map[string]Inputer),
 		connections:    make(map[string]connection),
+		recipes:        make(map[string]devicerecipe.Ingredients),
+		safeStates:     make(map[string]*safeOutputs),
 	}
 }
 
 // AddDevice creates a device from recipe and add it to the list
 func (di *RailDeviceAPI) AddDevice(deviceRecipe devicerecipe.Ingredients) (err error) {
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+
 	railDeviceKey := getKey(deviceRecipe.Name)
 	if _, ok := di.devices[railDeviceKey]; ok {
 		return fmt.Errorf("Rail device '%s' (key: %s) already in use", deviceRecipe.Name, railDeviceKey)
 	}
+	if err = verifySafeState(deviceRecipe); err != nil {
+		return
+	}
 	var inDev Inputer
 	var runDev *runableDevice
+	var outputs []*boardpin.Output
 	switch devicerecipe.TypeMap[deviceRecipe.Type] {
 	case devicerecipe.Button:
 		if inDev, err = di.createButton(deviceRecipe); err != nil {
@@ -80,36 +121,127 @@ func (di *RailDeviceAPI) AddDevice(deviceRecipe devicerecipe.Ingredients) (err e
 			return
 		}
 	case devicerecipe.Lamp:
-		if runDev, err = di.createLamp(deviceRecipe); err != nil {
+		if runDev, outputs, err = di.createLamp(deviceRecipe); err != nil {
 			return
 		}
 	case devicerecipe.TwoLightsSignal:
-		if runDev, err = di.createTwoLightSignal(deviceRecipe); err != nil {
+		if runDev, outputs, err = di.createTwoLightSignal(deviceRecipe); err != nil {
 			return
 		}
 	case devicerecipe.Turnout:
-		if runDev, err = di.createTurnout(deviceRecipe); err != nil {
+		if runDev, outputs, err = di.createTurnout(deviceRecipe); err != nil {
+			return
+		}
+	case devicerecipe.SemaphoreSignal:
+		if runDev, outputs, err = di.createSemaphoreSignal(deviceRecipe); err != nil {
+			return
+		}
+	case devicerecipe.ThreeLightsSignal, devicerecipe.FourLightsSignal:
+		if runDev, outputs, err = di.createMultiLightsSignal(deviceRecipe); err != nil {
+			return
+		}
+	case devicerecipe.BlinkingLamp:
+		if runDev, outputs, err = di.createBlinkingLamp(deviceRecipe); err != nil {
+			return
+		}
+	case devicerecipe.CrossingFlasher:
+		if runDev, outputs, err = di.createCrossingFlasher(deviceRecipe); err != nil {
+			return
+		}
+	case devicerecipe.DimmableLamp:
+		if runDev, outputs, err = di.createDimmableLamp(deviceRecipe); err != nil {
+			return
+		}
+	case devicerecipe.ServoTurnout:
+		if runDev, outputs, err = di.createServoTurnout(deviceRecipe); err != nil {
 			return
 		}
 	default:
 		return fmt.Errorf("Unknown type '%s'", deviceRecipe.Type)
 	}
+	if runDev != nil && deviceRecipe.Persist {
+		if runDev.storage, err = di.boardsIOAPI.GetStoragePin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrMem); err != nil {
+			return
+		}
+	}
 	if inDev != nil {
 		di.inputDevices[railDeviceKey] = inDev
 	}
 	if runDev != nil {
 		di.runableDevices[railDeviceKey] = runDev
+		di.safeStates[railDeviceKey] = newSafeOutputs(deviceRecipe, outputs)
 	}
 	if deviceRecipe.Connect != "" {
 		di.connections[railDeviceKey] = connection{name: getKey(deviceRecipe.Connect), inverse: deviceRecipe.Inverse}
 	}
 	di.devices[railDeviceKey] = struct{}{}
+	di.recipes[railDeviceKey] = deviceRecipe
+	di.order = append(di.order, railDeviceKey)
 	return
 }
 
-// ConnectNow create all connections
+// RemoveDevice removes the device from the list and releases the used board pins, all connections from and to
+// this device are dropped, so connected outputs are detached and will not run until connected again
+// pending steps of switching are completed and the outputs are set to the safe state before release, for "Last" the
+// outputs are switched off
+func (di *RailDeviceAPI) RemoveDevice(railDeviceName string) (err error) {
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+
+	railDeviceKey := getKey(railDeviceName)
+	if _, ok := di.devices[railDeviceKey]; !ok {
+		return fmt.Errorf("Rail device '%s' (key: %s) not found", railDeviceName, railDeviceKey)
+	}
+	recipe := di.recipes[railDeviceKey]
+	if runableDevice, ok := di.runableDevices[railDeviceKey]; ok {
+		if c, ok := runableDevice.Runner.(completer); ok {
+			err = c.CompletePending()
+		}
+	}
+	if safe, ok := di.safeStates[railDeviceKey]; ok {
+		err = errwrap.Wrap(err, safe.applyForRemove())
+	}
+	boardPinNrs := usedBoardPins(recipe)
+	if recipe.Persist {
+		boardPinNrs = append(boardPinNrs, recipe.BoardPinNrMem)
+	}
+	boardPinNrs = append(boardPinNrs, recipe.FeedbackBoardPins()...)
+	for _, boardPinNr := range boardPinNrs {
+		err = errwrap.Wrap(err, di.boardsIOAPI.ReleasePin(recipe.BoardID, boardPinNr))
+	}
+	for runningDevKey, conn := range di.connections {
+		if conn.name != railDeviceKey {
+			continue
+		}
+		if runableDevice, ok := di.runableDevices[runningDevKey]; ok {
+			runableDevice.ReleaseInput()
+		}
+		delete(di.connections, runningDevKey)
+	}
+	delete(di.connections, railDeviceKey)
+	delete(di.runableDevices, railDeviceKey)
+	delete(di.inputDevices, railDeviceKey)
+	delete(di.recipes, railDeviceKey)
+	delete(di.safeStates, railDeviceKey)
+	delete(di.devices, railDeviceKey)
+	for i, key := range di.order {
+		if key == railDeviceKey {
+			di.order = append(di.order[:i], di.order[i+1:]...)
+			break
+		}
+	}
+	return
+}
+
+// ConnectNow create all connections, already connected devices are skipped
 func (di *RailDeviceAPI) ConnectNow() (err error) {
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+
 	for runningDevKey, runableDevice := range di.runableDevices {
+		if runableDevice.IsConnected() {
+			continue
+		}
 		var conn connection
 		var ok bool
 		if conn, ok = di.connections[runningDevKey]; !ok {
@@ -129,16 +261,40 @@ func (di *RailDeviceAPI) ConnectNow() (err error) {
 	return
 }
 
-// Run calls the run functions of all runnable devices
+// Run calls the run functions of all runnable devices, devices without connected input are not switched
+// a device, which was never connected, is reported once
+// an error of a device does not prevent running of all other devices, all errors are returned together
+// after run all devices with time dependent outputs are ticked with the same time of this cycle, also the not
+// connected ones, so e.g. a coil pulse is finished after removing the input device
 func (di *RailDeviceAPI) Run() (err error) {
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+
+	now := time.Now()
 	for _, runableDevice := range di.runableDevices {
-		if err = runableDevice.Run(); err != nil {
-			return err
-		}
+		err = errwrap.Wrap(err, runableDevice.Run())
+		err = errwrap.Wrap(err, runableDevice.Tick(now))
 	}
 	return
 }
 
+// SetAspect shows the given aspect at the device, the device needs to provide named aspects
+// a later change of the connected input will switch to the aspect for "on" or "off" again
+func (di *RailDeviceAPI) SetAspect(railDeviceName string, aspect string) (err error) {
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+
+	runableDevice, ok := di.runableDevices[getKey(railDeviceName)]
+	if !ok {
+		return fmt.Errorf("Rail device '%s' not found", railDeviceName)
+	}
+	aspecter, ok := runableDevice.Runner.(Aspecter)
+	if !ok {
+		return fmt.Errorf("The '%s' provides no aspects", railDeviceName)
+	}
+	return aspecter.SetAspect(aspect)
+}
+
 func (di *RailDeviceAPI) createButton(deviceRecipe devicerecipe.Ingredients) (button Inputer, err error) {
 	var input *boardpin.Input
 	if input, err = di.boardsIOAPI.GetInputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
@@ -157,7 +313,8 @@ func (di *RailDeviceAPI) createToggleButton(deviceRecipe devicerecipe.Ingredient
 	return
 }
 
-func (di *RailDeviceAPI) createLamp(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice, err error) {
+func (di *RailDeviceAPI) createLamp(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
 	var output *boardpin.Output
 	if output, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
 		return
@@ -165,10 +322,12 @@ func (di *RailDeviceAPI) createLamp(deviceRecipe devicerecipe.Ingredients) (rd *
 	co := raildevices.NewCommonOutput(deviceRecipe.Name, getTiming(deviceRecipe))
 	lamp := raildevices.NewLamp(co, output)
 	rd = newRunableDevice(lamp)
+	outputs = []*boardpin.Output{output}
 	return
 }
 
-func (di *RailDeviceAPI) createTwoLightSignal(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice, err error) {
+func (di *RailDeviceAPI) createTwoLightSignal(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
 	var outputPass *boardpin.Output
 	if outputPass, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
 		return
@@ -180,10 +339,12 @@ func (di *RailDeviceAPI) createTwoLightSignal(deviceRecipe devicerecipe.Ingredie
 	co := raildevices.NewCommonOutput(deviceRecipe.Name, getTiming(deviceRecipe))
 	signal := raildevices.NewTwoLightsSignal(co, outputPass, outputStop)
 	rd = newRunableDevice(signal)
+	outputs = []*boardpin.Output{outputPass, outputStop}
 	return
 }
 
-func (di *RailDeviceAPI) createTurnout(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice, err error) {
+func (di *RailDeviceAPI) createTurnout(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
 	var outputBranch *boardpin.Output
 	if outputBranch, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
 		return
@@ -195,8 +356,165 @@ func (di *RailDeviceAPI) createTurnout(deviceRecipe devicerecipe.Ingredients) (r
 	timing := getTiming(deviceRecipe)
 	timing.Limit(time.Duration(1 * time.Second))
 	co := raildevices.NewCommonOutput(deviceRecipe.Name, timing)
-	turnout := raildevices.NewTurnout(co, outputBranch, outputMain)
+	outputs = []*boardpin.Output{outputBranch, outputMain}
+	if !deviceRecipe.HasFeedback() {
+		rd = newRunableDevice(raildevices.NewTurnout(co, outputBranch, outputMain))
+		return
+	}
+	// a position without feedback input is not verified
+	feedback := raildevices.TurnoutFeedback{Timeout: defaultFeedbackTimeout}
+	if deviceRecipe.FeedbackBranch {
+		if feedback.Branch, err = di.boardsIOAPI.GetInputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrFbBranch); err != nil {
+			return nil, nil, err
+		}
+	}
+	if deviceRecipe.FeedbackMain {
+		if feedback.Main, err = di.boardsIOAPI.GetInputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrFbMain); err != nil {
+			return nil, nil, err
+		}
+	}
+	if timeout, err := time.ParseDuration(deviceRecipe.FeedbackTimeout); err == nil {
+		feedback.Timeout = timeout
+	}
+	rd = newRunableDevice(raildevices.NewTurnoutWithFeedback(co, outputBranch, outputMain, feedback))
+	return
+}
+
+func (di *RailDeviceAPI) createSemaphoreSignal(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
+	var outputPass *boardpin.Output
+	if outputPass, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
+		return
+	}
+	var outputStop *boardpin.Output
+	if outputStop, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrSec); err != nil {
+		return
+	}
+	outputs = []*boardpin.Output{outputPass, outputStop}
+	var outputAux *boardpin.Output
+	if deviceRecipe.AuxOutput {
+		if outputAux, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrAux); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, outputAux)
+	}
+	timing := getTiming(deviceRecipe)
+	timing.Limit(time.Duration(1 * time.Second))
+	co := raildevices.NewCommonOutput(deviceRecipe.Name, timing)
+	signal := raildevices.NewSemaphoreSignal(co, outputPass, outputStop, outputAux)
+	rd = newRunableDevice(signal)
+	return
+}
+
+func (di *RailDeviceAPI) createMultiLightsSignal(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
+	for _, boardPinNr := range usedBoardPins(deviceRecipe) {
+		var output *boardpin.Output
+		if output, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, boardPinNr); err != nil {
+			return nil, nil, err
+		}
+		outputs = append(outputs, output)
+	}
+	aspects := raildevices.ThreeLightsAspects
+	if devicerecipe.TypeMap[deviceRecipe.Type] == devicerecipe.FourLightsSignal {
+		aspects = raildevices.FourLightsAspects
+	}
+	if len(deviceRecipe.Aspects) > 0 {
+		if aspects, err = devicerecipe.ParseAspects(deviceRecipe.Aspects); err != nil {
+			return nil, nil, err
+		}
+	}
+	aspectOn, aspectOff := deviceRecipe.AspectOn, deviceRecipe.AspectOff
+	if aspectOn == "" {
+		aspectOn = "Hp1"
+	}
+	if aspectOff == "" {
+		aspectOff = "Hp0"
+	}
+	co := raildevices.NewCommonOutput(deviceRecipe.Name, getTiming(deviceRecipe))
+	var signal *raildevices.MultiLightsSignalDevice
+	if signal, err = raildevices.NewMultiLightsSignal(co, outputs, aspects, aspectOn, aspectOff); err != nil {
+		return nil, nil, err
+	}
+	rd = newRunableDevice(signal)
+	return
+}
+
+func (di *RailDeviceAPI) createBlinkingLamp(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
+	var output *boardpin.Output
+	if output, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
+		return
+	}
+	co := raildevices.NewCommonOutput(deviceRecipe.Name, raildevices.Timing{})
+	lamp := raildevices.NewBlinkingLamp(co, output, getBlinking(deviceRecipe))
+	rd = newRunableDevice(lamp)
+	outputs = []*boardpin.Output{output}
+	return
+}
+
+func (di *RailDeviceAPI) createCrossingFlasher(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
+	var outputFirst *boardpin.Output
+	if outputFirst, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
+		return
+	}
+	var outputSecond *boardpin.Output
+	if outputSecond, err = di.boardsIOAPI.GetOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrSec); err != nil {
+		return
+	}
+	co := raildevices.NewCommonOutput(deviceRecipe.Name, raildevices.Timing{})
+	flasher := raildevices.NewCrossingFlasher(co, outputFirst, outputSecond, getBlinking(deviceRecipe))
+	rd = newRunableDevice(flasher)
+	outputs = []*boardpin.Output{outputFirst, outputSecond}
+	return
+}
+
+func (di *RailDeviceAPI) createDimmableLamp(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
+	var output *boardpin.Output
+	if output, err = di.boardsIOAPI.GetAnalogOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
+		return
+	}
+	co := raildevices.NewCommonOutput(deviceRecipe.Name, getTiming(deviceRecipe))
+	lamp := raildevices.NewDimmableLamp(co, output)
+	rd = newRunableDevice(lamp)
+	outputs = []*boardpin.Output{output}
+	return
+}
+
+func (di *RailDeviceAPI) createServoTurnout(deviceRecipe devicerecipe.Ingredients) (rd *runableDevice,
+	outputs []*boardpin.Output, err error) {
+	var output *boardpin.Output
+	if output, err = di.boardsIOAPI.GetServoOutputPin(deviceRecipe.BoardID, deviceRecipe.BoardPinNrPrim); err != nil {
+		return
+	}
+	positions := raildevices.ServoPositions{Main: deviceRecipe.PositionMain, Branch: deviceRecipe.PositionBranch}
+	co := raildevices.NewCommonOutput(deviceRecipe.Name, raildevices.Timing{})
+	turnout := raildevices.NewServoTurnout(co, output, positions, getServoTravelTime(deviceRecipe))
 	rd = newRunableDevice(turnout)
+	// no safe state is written, because the servo keeps the position and any value would move the servo
+	return
+}
+
+// usedBoardPins gets the board pin numbers, which are used by the device created from the recipe
+func usedBoardPins(r devicerecipe.Ingredients) (boardPinNrs []uint8) {
+	switch devicerecipe.TypeMap[r.Type] {
+	case devicerecipe.Button, devicerecipe.ToggleButton, devicerecipe.Lamp, devicerecipe.BlinkingLamp,
+		devicerecipe.DimmableLamp, devicerecipe.ServoTurnout:
+		boardPinNrs = []uint8{r.BoardPinNrPrim}
+	case devicerecipe.TwoLightsSignal, devicerecipe.Turnout, devicerecipe.CrossingFlasher:
+		boardPinNrs = []uint8{r.BoardPinNrPrim, r.BoardPinNrSec}
+	case devicerecipe.ThreeLightsSignal:
+		boardPinNrs = []uint8{r.BoardPinNrPrim, r.BoardPinNrSec, r.BoardPinNrThird}
+	case devicerecipe.FourLightsSignal:
+		boardPinNrs = []uint8{r.BoardPinNrPrim, r.BoardPinNrSec, r.BoardPinNrThird, r.BoardPinNrFourth}
+	case devicerecipe.SemaphoreSignal:
+		boardPinNrs = []uint8{r.BoardPinNrPrim, r.BoardPinNrSec}
+		if r.AuxOutput {
+			boardPinNrs = append(boardPinNrs, r.BoardPinNrAux)
+		}
+	}
 	return
 }
 
@@ -205,8 +523,34 @@ func getKey(railDeviceName string) (railDeviceKey string) {
 	return
 }
 
+// getTiming gets the delays for start and stop, without a stop delay the start delay is used for both like before,
+// so e.g. the main pulse of a turnout is never shortened to zero
 func getTiming(r devicerecipe.Ingredients) raildevices.Timing {
 	start, _ := time.ParseDuration(r.StartingDelay)
-	stop, _ := time.ParseDuration(r.StartingDelay)
+	stop := start
+	if r.StoppingDelay != "" {
+		stop, _ = time.ParseDuration(r.StoppingDelay)
+	}
 	return raildevices.Timing{Starting: start, Stopping: stop}
 }
+
+// getBlinking gets the periods of a blinking device, a not given period is set to the default
+func getBlinking(r devicerecipe.Ingredients) raildevices.Blinking {
+	blinking := raildevices.Blinking{On: defaultBlinkPeriod, Off: defaultBlinkPeriod}
+	if on, err := time.ParseDuration(r.OnPeriod); err == nil {
+		blinking.On = on
+	}
+	if off, err := time.ParseDuration(r.OffPeriod); err == nil {
+		blinking.Off = off
+	}
+	return blinking
+}
+
+// getServoTravelTime gets the duration for the full range of positions by the travel speed
+func getServoTravelTime(r devicerecipe.Ingredients) time.Duration {
+	speed := r.TravelSpeed
+	if speed == 0 {
+		speed = defaultServoSpeed
+	}
+	return time.Duration(255) * time.Second / time.Duration(speed)
+}
@@ -3,15 +3,24 @@ package raildevicesapi
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gen2thomas/gobrail/internal/boardpin"
+	"github.com/gen2thomas/gobrail/internal/boardrecipe"
+	"github.com/gen2thomas/gobrail/internal/boardsapi"
 	"github.com/gen2thomas/gobrail/internal/devicerecipe"
+	"github.com/gen2thomas/gobrail/internal/raildevices"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-type boardsIOAPIMock struct{}
+type boardsIOAPIMock struct {
+	releasedPins map[uint8]struct{}
+	// optional, records the last value written to each pin before the pin was released
+	writtenValues map[uint8]uint8
+}
 
 type inputerMock struct {
 	simStateChangedErr bool
@@ -24,6 +33,11 @@ type runnerMock struct {
 	simOffErr bool
 }
 
+type tickerMock struct {
+	runnerMock
+	ticks *[]time.Time
+}
+
 func TestNewRailDevicesAPI(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
@@ -37,6 +51,7 @@ func TestNewRailDevicesAPI(t *testing.T) {
 	assert.NotNil(da.runableDevices)
 	assert.NotNil(da.inputDevices)
 	assert.NotNil(da.connections)
+	assert.NotNil(da.recipes)
 	assert.Equal(ba, da.boardsIOAPI)
 }
 
@@ -73,6 +88,13 @@ func TestAddDevice(t *testing.T) {
 		"AddLamp":            {Name: "test_device", Type: "Lamp", BoardID: "test_board", BoardPinNrPrim: 2},
 		"AddTwoLightsSignal": {Name: "test_device", Type: "TwoLightsSignal", BoardID: "test_board", BoardPinNrPrim: 3, BoardPinNrSec: 4},
 		"AddTurnout":         {Name: "test_device", Type: "Turnout", BoardID: "test_board", BoardPinNrPrim: 5, BoardPinNrSec: 6, Connect: "test_connect"},
+		"AddThreeLights":     {Name: "test_device", Type: "ThreeLightsSignal", BoardID: "test_board", BoardPinNrPrim: 1, BoardPinNrSec: 2, BoardPinNrThird: 3},
+		"AddFourLights":      {Name: "test_device", Type: "FourLightsSignal", BoardID: "test_board", BoardPinNrPrim: 1, BoardPinNrSec: 2, BoardPinNrThird: 3, BoardPinNrFourth: 4, AspectOn: "Hp2"},
+		"AddSemaphoreSignal": {Name: "test_device", Type: "SemaphoreSignal", BoardID: "test_board", BoardPinNrPrim: 5, BoardPinNrSec: 6, AuxOutput: true, BoardPinNrAux: 7},
+		"AddBlinkingLamp":    {Name: "test_device", Type: "BlinkingLamp", BoardID: "test_board", BoardPinNrPrim: 2, OnPeriod: "1s"},
+		"AddCrossingFlasher": {Name: "test_device", Type: "CrossingFlasher", BoardID: "test_board", BoardPinNrPrim: 3, BoardPinNrSec: 4},
+		"AddServoTurnout":    {Name: "test_device", Type: "ServoTurnout", BoardID: "test_board", BoardPinNrPrim: 6, PositionMain: 20, PositionBranch: 30},
+		"AddDimmableLamp":    {Name: "test_device", Type: "DimmableLamp", BoardID: "test_board", BoardPinNrPrim: 5, StartingDelay: "2s"},
 	}
 	for name, at := range addDeviceTests {
 		t.Run(name, func(t *testing.T) {
@@ -85,18 +107,27 @@ func TestAddDevice(t *testing.T) {
 			da.inputDevices = make(map[string]Inputer)
 			da.runableDevices = make(map[string]*runableDevice)
 			da.connections = make(map[string]connection)
+			da.recipes = make(map[string]devicerecipe.Ingredients)
+			da.safeStates = make(map[string]*safeOutputs)
 			// act
 			err := da.AddDevice(at)
 			// assert
 			require.Nil(err)
 			assert.Contains(da.devices, "test_device")
+			assert.Equal(at, da.recipes["test_device"])
 			if strings.Contains(name, "Button") {
 				assert.Contains(da.inputDevices, "test_device")
 				assert.NotContains(da.runableDevices, "test_device")
+				assert.NotContains(da.safeStates, "test_device")
+			} else if name == "AddServoTurnout" {
+				assert.Contains(da.runableDevices, "test_device")
+				assert.Empty(da.safeStates["test_device"].outputs)
 			} else {
 				assert.Contains(da.runableDevices, "test_device")
 				assert.NotContains(da.inputDevices, "test_device")
+				assert.Equal(len(usedBoardPins(at)), len(da.safeStates["test_device"].outputs))
 			}
+			assert.Equal([]string{"test_device"}, da.order)
 			if at.Connect != "" {
 				assert.Equal("test_connect", da.connections["test_device"].name)
 			} else {
@@ -153,6 +184,353 @@ func TestConnectNowWhenConnectGetsErrorReturnsError(t *testing.T) {
 	assert.Contains(err.Error(), "Circular mapping blocked for 'rdk'")
 }
 
+func TestConnectNowSkipsConnectedDevices(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{}
+	im1 := &inputerMock{}
+	im2 := &inputerMock{isOn: true}
+	da.inputDevices = map[string]Inputer{"inp_dev_key": im2}
+	da.runableDevices = map[string]*runableDevice{"run_dev_key": &runableDevice{Runner: runnerMock{name: "rdk"}, connectedInput: im1}}
+	da.connections = map[string]connection{"run_dev_key": connection{name: "inp_dev_key"}}
+	// act
+	err := da.ConnectNow()
+	// assert
+	require.Nil(err)
+	assert.Equal(im1, da.runableDevices["run_dev_key"].connectedInput)
+}
+
+func TestRunReportsNeverConnectedDevicesOnce(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{}
+	da.runableDevices = map[string]*runableDevice{
+		"run_dev_key":  &runableDevice{Runner: runnerMock{name: "rdk"}},
+		"conn_dev_key": &runableDevice{Runner: runnerMock{name: "cdk"}, connectedInput: &inputerMock{}},
+	}
+	// act
+	err := da.Run()
+	errSecond := da.Run()
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "'rdk' can't run")
+	require.Nil(errSecond)
+}
+
+func TestRunContinuesAfterDeviceError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{}
+	da.runableDevices = map[string]*runableDevice{
+		"err_dev_key": &runableDevice{Runner: runnerMock{name: "edk", simOffErr: true}, connectedInput: &inputerMock{}, firstRun: true},
+		"run_dev_key": &runableDevice{Runner: runnerMock{name: "rdk"}, connectedInput: &inputerMock{}, firstRun: true},
+	}
+	// act
+	err := da.Run()
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "off error")
+	assert.False(da.runableDevices["run_dev_key"].firstRun)
+}
+
+func TestRunTicksAllDevices(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	var ticksConn, ticksNotConn []time.Time
+	da := RailDeviceAPI{}
+	da.runableDevices = map[string]*runableDevice{
+		"run_dev_key":  &runableDevice{Runner: tickerMock{runnerMock: runnerMock{name: "rdk"}, ticks: &ticksNotConn}, wasConnected: true},
+		"conn_dev_key": &runableDevice{Runner: tickerMock{runnerMock: runnerMock{name: "cdk"}, ticks: &ticksConn}, connectedInput: &inputerMock{}},
+	}
+	// act
+	err := da.Run()
+	errSecond := da.Run()
+	// assert
+	require.Nil(err)
+	require.Nil(errSecond)
+	require.Equal(2, len(ticksConn))
+	assert.False(ticksConn[1].Before(ticksConn[0]))
+	assert.Equal(ticksConn, ticksNotConn)
+}
+
+func TestRunFinishesPulseAfterRemovingInputDevice(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{releasedPins: make(map[uint8]struct{}), writtenValues: make(map[uint8]uint8)}
+	da := NewRailDevicesAPI(ba)
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "turnout", Type: "Turnout", BoardID: "test_board",
+		BoardPinNrPrim: 2, BoardPinNrSec: 3, StartingDelay: "1ms", Connect: "button"}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "button", Type: "Button", BoardID: "test_board", BoardPinNrPrim: 1}))
+	da.inputDevices["button"] = &inputerMock{isOn: true}
+	require.Nil(da.ConnectNow())
+	require.Nil(da.Run())
+	require.Equal(uint8(1), ba.writtenValues[2])
+	// act
+	err := da.RemoveDevice("button")
+	time.Sleep(2 * time.Millisecond)
+	errRun := da.Run()
+	// assert
+	require.Nil(err)
+	require.Nil(errRun)
+	assert.Equal(uint8(0), ba.writtenValues[2])
+	assert.True(da.runableDevices["turnout"].IsOn())
+}
+
+func TestRemoveDeviceFinishesPulse(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{releasedPins: make(map[uint8]struct{}), writtenValues: make(map[uint8]uint8)}
+	da := NewRailDevicesAPI(ba)
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "turnout", Type: "Turnout", BoardID: "test_board",
+		BoardPinNrPrim: 2, BoardPinNrSec: 3, StartingDelay: "1s", SafeState: "Last", Connect: "button"}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "button", Type: "Button", BoardID: "test_board", BoardPinNrPrim: 1}))
+	da.inputDevices["button"] = &inputerMock{isOn: true}
+	require.Nil(da.ConnectNow())
+	require.Nil(da.Run())
+	require.Equal(uint8(1), ba.writtenValues[2])
+	// act
+	err := da.RemoveDevice("turnout")
+	// assert
+	require.Nil(err)
+	assert.Equal(map[uint8]uint8{2: 0, 3: 0}, ba.writtenValues)
+}
+
+func TestRemoveDevice(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{releasedPins: make(map[uint8]struct{})}
+	da := NewRailDevicesAPI(ba)
+	// button --> signal --> lamp
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "button", Type: "Button", BoardID: "test_board", BoardPinNrPrim: 1}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "signal", Type: "TwoLightsSignal", BoardID: "test_board", BoardPinNrPrim: 2, BoardPinNrSec: 3, Connect: "button"}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp", Type: "Lamp", BoardID: "test_board", BoardPinNrPrim: 4, Connect: "signal"}))
+	require.Nil(da.ConnectNow())
+	// act
+	err := da.RemoveDevice("Signal")
+	// assert
+	require.Nil(err)
+	assert.Equal(map[uint8]struct{}{2: {}, 3: {}}, ba.releasedPins)
+	assert.NotContains(da.devices, "signal")
+	assert.NotContains(da.runableDevices, "signal")
+	assert.NotContains(da.recipes, "signal")
+	assert.Empty(da.connections)
+	assert.Contains(da.inputDevices, "button")
+	require.Contains(da.runableDevices, "lamp")
+	assert.False(da.runableDevices["lamp"].IsConnected())
+	assert.Nil(da.ConnectNow())
+	assert.Nil(da.Run())
+}
+
+func TestAddAndRemoveDevicePersisted(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{releasedPins: make(map[uint8]struct{})}
+	da := NewRailDevicesAPI(ba)
+	recipe := devicerecipe.Ingredients{Name: "turnout", Type: "Turnout", BoardID: "test_board", BoardPinNrPrim: 2,
+		BoardPinNrSec: 3, Persist: true, BoardPinNrMem: 8}
+	// act
+	errAdd := da.AddDevice(recipe)
+	require.Contains(da.runableDevices, "turnout")
+	storage := da.runableDevices["turnout"].storage
+	errRemove := da.RemoveDevice("turnout")
+	// assert
+	require.Nil(errAdd)
+	require.Nil(errRemove)
+	assert.NotNil(storage)
+	assert.Equal(map[uint8]struct{}{2: {}, 3: {}, 8: {}}, ba.releasedPins)
+}
+
+func TestAddAndRemoveDeviceWithFeedback(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{releasedPins: make(map[uint8]struct{})}
+	da := NewRailDevicesAPI(ba)
+	recipe := devicerecipe.Ingredients{Name: "turnout", Type: "Turnout", BoardID: "test_board", BoardPinNrPrim: 2,
+		BoardPinNrSec: 3, FeedbackBranch: true, BoardPinNrFbBranch: 4, FeedbackMain: true, BoardPinNrFbMain: 5}
+	// act
+	errAdd := da.AddDevice(recipe)
+	require.Contains(da.runableDevices, "turnout")
+	safeOutputsCount := len(da.safeStates["turnout"].outputs)
+	errRemove := da.RemoveDevice("turnout")
+	// assert
+	require.Nil(errAdd)
+	require.Nil(errRemove)
+	assert.Equal(2, safeOutputsCount)
+	assert.Equal(map[uint8]struct{}{2: {}, 3: {}, 4: {}, 5: {}}, ba.releasedPins)
+}
+
+func TestAddAndRemoveDeviceWithFeedbackOnlyForMain(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{releasedPins: make(map[uint8]struct{})}
+	da := NewRailDevicesAPI(ba)
+	recipe := devicerecipe.Ingredients{Name: "turnout", Type: "Turnout", BoardID: "test_board", BoardPinNrPrim: 2,
+		BoardPinNrSec: 3, FeedbackMain: true, BoardPinNrFbMain: 5}
+	// act
+	errAdd := da.AddDevice(recipe)
+	errRemove := da.RemoveDevice("turnout")
+	// assert
+	require.Nil(errAdd)
+	require.Nil(errRemove)
+	assert.Equal(map[uint8]struct{}{2: {}, 3: {}, 5: {}}, ba.releasedPins)
+}
+
+func Test_createTurnoutWithFeedbackGetInputPinErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{boardsIOAPI: &boardsIOAPIMock{}}
+	// act
+	rd, outputs, err := da.createTurnout(devicerecipe.Ingredients{BoardID: "error", FeedbackMain: true})
+	// assert
+	require.NotNil(err)
+	assert.Nil(rd)
+	assert.Nil(outputs)
+	assert.Equal("test error", err.Error())
+}
+
+func TestAddDevicePersistedWhenGetStoragePinErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := NewRailDevicesAPI(&boardsIOAPIMock{})
+	recipe := devicerecipe.Ingredients{Name: "turnout", Type: "Turnout", BoardID: "error", BoardPinNrPrim: 2,
+		BoardPinNrSec: 3, Persist: true, BoardPinNrMem: 88}
+	// act
+	err := da.AddDevice(recipe)
+	// assert
+	require.NotNil(err)
+	assert.Equal("test error", err.Error())
+	assert.NotContains(da.devices, "turnout")
+}
+
+func TestRemoveDeviceSwitchesOffBeforeRelease(t *testing.T) {
+	var tests = map[string]struct {
+		safeState string
+		want      map[uint8]uint8
+	}{
+		"off":  {safeState: "", want: map[uint8]uint8{4: 0, 5: 0}},
+		"last": {safeState: "Last", want: map[uint8]uint8{4: 0, 5: 0}},
+		"on":   {safeState: "1", want: map[uint8]uint8{4: 1, 5: 1}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			ba := &boardsIOAPIMock{releasedPins: make(map[uint8]struct{}), writtenValues: make(map[uint8]uint8)}
+			da := NewRailDevicesAPI(ba)
+			require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "signal", Type: "TwoLightsSignal", BoardID: "test_board",
+				BoardPinNrPrim: 4, BoardPinNrSec: 5, SafeState: tc.safeState}))
+			// act
+			err := da.RemoveDevice("signal")
+			// assert
+			require.Nil(err)
+			assert.Equal(tc.want, ba.writtenValues)
+			assert.Equal(map[uint8]struct{}{4: {}, 5: {}}, ba.releasedPins)
+		})
+	}
+}
+
+func TestRemoveDeviceUnknownGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := NewRailDevicesAPI(&boardsIOAPIMock{})
+	// act
+	err := da.RemoveDevice("unknown device")
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "not found")
+}
+
+func TestRemoveDeviceWhenReleasePinErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{releasedPins: make(map[uint8]struct{})}
+	da := NewRailDevicesAPI(ba)
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp", Type: "Lamp", BoardID: "error", BoardPinNrPrim: 4}))
+	// act
+	err := da.RemoveDevice("lamp")
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "release error")
+	assert.NotContains(da.devices, "lamp")
+}
+
+func TestConcurrentUse(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := boardsapi.NewBoardsAPI(nil)
+	require.Nil(ba.AddBoard(boardrecipe.Ingredients{Name: "test_board", Type: "Virtual8io"}))
+	da := NewRailDevicesAPI(ba)
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "button", Type: "Button", BoardID: "test_board", BoardPinNrPrim: 0}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp", Type: "Lamp", BoardID: "test_board", BoardPinNrPrim: 1, Connect: "button"}))
+	require.Nil(da.ConnectNow())
+	var wg sync.WaitGroup
+	// act
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ba.BeginCycle()
+			da.Run()
+			ba.EndCycle()
+		}()
+		go func() {
+			defer wg.Done()
+			da.AddDevice(devicerecipe.Ingredients{Name: "lamp2", Type: "Lamp", BoardID: "test_board", BoardPinNrPrim: 2, Connect: "lamp"})
+			da.ConnectNow()
+			da.RemoveDevice("lamp2")
+		}()
+	}
+	wg.Wait()
+	// assert
+	assert.Equal(2, len(da.devices))
+	assert.Equal(boardpin.PinNumbers{0: struct{}{}, 1: struct{}{}}, ba.GetUsedPins("test_board"))
+}
+
+func Test_usedBoardPins(t *testing.T) {
+	var usedBoardPinsTests = map[string]struct {
+		recipe devicerecipe.Ingredients
+		want   []uint8
+	}{
+		"Button":          {recipe: devicerecipe.Ingredients{Type: "Button", BoardPinNrPrim: 1, BoardPinNrSec: 2}, want: []uint8{1}},
+		"Lamp":            {recipe: devicerecipe.Ingredients{Type: "Lamp", BoardPinNrPrim: 3}, want: []uint8{3}},
+		"TwoLightsSignal": {recipe: devicerecipe.Ingredients{Type: "TwoLightsSignal", BoardPinNrPrim: 4, BoardPinNrSec: 5}, want: []uint8{4, 5}},
+		"Turnout":         {recipe: devicerecipe.Ingredients{Type: "Turnout", BoardPinNrPrim: 6, BoardPinNrSec: 7}, want: []uint8{6, 7}},
+		"ThreeLights":     {recipe: devicerecipe.Ingredients{Type: "ThreeLightsSignal", BoardPinNrPrim: 1, BoardPinNrSec: 2, BoardPinNrThird: 3}, want: []uint8{1, 2, 3}},
+		"FourLights":      {recipe: devicerecipe.Ingredients{Type: "FourLightsSignal", BoardPinNrPrim: 1, BoardPinNrSec: 2, BoardPinNrThird: 3, BoardPinNrFourth: 4}, want: []uint8{1, 2, 3, 4}},
+		"SemaphoreSignal": {recipe: devicerecipe.Ingredients{Type: "SemaphoreSignal", BoardPinNrPrim: 6, BoardPinNrSec: 7}, want: []uint8{6, 7}},
+		"SemaphoreAux":    {recipe: devicerecipe.Ingredients{Type: "SemaphoreSignal", BoardPinNrPrim: 6, BoardPinNrSec: 7, AuxOutput: true, BoardPinNrAux: 8}, want: []uint8{6, 7, 8}},
+		"BlinkingLamp":    {recipe: devicerecipe.Ingredients{Type: "BlinkingLamp", BoardPinNrPrim: 3, BoardPinNrSec: 4}, want: []uint8{3}},
+		"CrossingFlasher": {recipe: devicerecipe.Ingredients{Type: "CrossingFlasher", BoardPinNrPrim: 4, BoardPinNrSec: 5}, want: []uint8{4, 5}},
+		"ServoTurnout":    {recipe: devicerecipe.Ingredients{Type: "ServoTurnout", BoardPinNrPrim: 8}, want: []uint8{8}},
+		"DimmableLamp":    {recipe: devicerecipe.Ingredients{Type: "DimmableLamp", BoardPinNrPrim: 6, BoardPinNrSec: 7}, want: []uint8{6}},
+		"Unknown":         {recipe: devicerecipe.Ingredients{Type: "TypUnknown"}, want: nil},
+	}
+	for name, ut := range usedBoardPinsTests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got := usedBoardPins(ut.recipe)
+			// assert
+			assert.Equal(t, ut.want, got)
+		})
+	}
+}
+
 func Test_createButton(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
@@ -217,7 +595,7 @@ func Test_createLamp(t *testing.T) {
 	da := RailDeviceAPI{boardsIOAPI: ba}
 	da.devices = make(map[string]struct{})
 	// act
-	outp, err := da.createLamp(devicerecipe.Ingredients{})
+	outp, _, err := da.createLamp(devicerecipe.Ingredients{})
 	// assert
 	require.Nil(err)
 	assert.NotNil(outp)
@@ -231,7 +609,7 @@ func Test_createLampGetOutputPinErrorGetsError(t *testing.T) {
 	da := RailDeviceAPI{boardsIOAPI: ba}
 	da.devices = make(map[string]struct{})
 	// act
-	_, err := da.createLamp(devicerecipe.Ingredients{BoardID: "error", BoardPinNrPrim: 88})
+	_, _, err := da.createLamp(devicerecipe.Ingredients{BoardID: "error", BoardPinNrPrim: 88})
 	// assert
 	require.NotNil(err)
 	assert.Equal("test error", err.Error())
@@ -245,7 +623,7 @@ func Test_createTwoLightSignal(t *testing.T) {
 	da := RailDeviceAPI{boardsIOAPI: ba}
 	da.devices = make(map[string]struct{})
 	// act
-	outp, err := da.createTwoLightSignal(devicerecipe.Ingredients{})
+	outp, _, err := da.createTwoLightSignal(devicerecipe.Ingredients{})
 	// assert
 	require.Nil(err)
 	assert.NotNil(outp)
@@ -259,7 +637,7 @@ func Test_createTwoLightSignalGetOutPinPrimErrorGetsError(t *testing.T) {
 	da := RailDeviceAPI{boardsIOAPI: ba}
 	da.devices = make(map[string]struct{})
 	// act
-	_, err := da.createTwoLightSignal(devicerecipe.Ingredients{BoardID: "error", BoardPinNrPrim: 88})
+	_, _, err := da.createTwoLightSignal(devicerecipe.Ingredients{BoardID: "error", BoardPinNrPrim: 88})
 	// assert
 	require.NotNil(err)
 	assert.Equal("test error", err.Error())
@@ -273,7 +651,7 @@ func Test_createTwoLightSignalGetOutPinSecErrorGetsError(t *testing.T) {
 	da := RailDeviceAPI{boardsIOAPI: ba}
 	da.devices = make(map[string]struct{})
 	// act
-	_, err := da.createTwoLightSignal(devicerecipe.Ingredients{BoardID: "error", BoardPinNrSec: 88})
+	_, _, err := da.createTwoLightSignal(devicerecipe.Ingredients{BoardID: "error", BoardPinNrSec: 88})
 	// assert
 	require.NotNil(err)
 	assert.Equal("test error", err.Error())
@@ -287,12 +665,193 @@ func Test_createTurnout(t *testing.T) {
 	da := RailDeviceAPI{boardsIOAPI: ba}
 	da.devices = make(map[string]struct{})
 	// act
-	outp, err := da.createTurnout(devicerecipe.Ingredients{})
+	outp, _, err := da.createTurnout(devicerecipe.Ingredients{})
 	// assert
 	require.Nil(err)
 	assert.NotNil(outp)
 }
 
+func Test_createSemaphoreSignal(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{}
+	da := RailDeviceAPI{boardsIOAPI: ba}
+	// act
+	rd, outputs, err := da.createSemaphoreSignal(devicerecipe.Ingredients{AuxOutput: true, BoardPinNrAux: 3,
+		StartingDelay: "2s"})
+	// assert
+	require.Nil(err)
+	assert.NotNil(rd)
+	assert.Equal(3, len(outputs))
+}
+
+func Test_createSemaphoreSignalGetOutPinAuxErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := &boardsIOAPIMock{}
+	da := RailDeviceAPI{boardsIOAPI: ba}
+	// act
+	rd, _, err := da.createSemaphoreSignal(devicerecipe.Ingredients{BoardID: "error", AuxOutput: true,
+		BoardPinNrAux: 88})
+	// assert
+	require.NotNil(err)
+	assert.Nil(rd)
+	assert.Equal("test error", err.Error())
+}
+
+func Test_createMultiLightsSignalWithAspects(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{boardsIOAPI: &boardsIOAPIMock{}}
+	recipe := devicerecipe.Ingredients{Type: "ThreeLightsSignal", Aspects: map[string]string{"Stop": "1,0,0",
+		"Go": "0,1,0"}, AspectOn: "Go", AspectOff: "Stop"}
+	// act
+	rd, outputs, err := da.createMultiLightsSignal(recipe)
+	// assert
+	require.Nil(err)
+	assert.Equal(3, len(outputs))
+	assert.Equal([]string{"Go", "Stop"}, rd.Runner.(Aspecter).Aspects())
+}
+
+func Test_createMultiLightsSignalWithUnknownAspectGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{boardsIOAPI: &boardsIOAPIMock{}}
+	recipe := devicerecipe.Ingredients{Type: "FourLightsSignal", AspectOn: "Hp3"}
+	// act
+	rd, _, err := da.createMultiLightsSignal(recipe)
+	// assert
+	require.NotNil(err)
+	assert.Nil(rd)
+	assert.Contains(err.Error(), "aspect 'Hp3' is unknown")
+}
+
+func TestSetAspect(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := NewRailDevicesAPI(&boardsIOAPIMock{})
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "Signal 1", Type: "FourLightsSignal", BoardID: "test_board",
+		BoardPinNrPrim: 1, BoardPinNrSec: 2, BoardPinNrThird: 3, BoardPinNrFourth: 4}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp", Type: "Lamp", BoardID: "test_board", BoardPinNrPrim: 5}))
+	// act
+	err := da.SetAspect("Signal 1", "Sh1")
+	errUnknownAspect := da.SetAspect("Signal 1", "Zs1")
+	errNoAspects := da.SetAspect("lamp", "Hp0")
+	errUnknownDevice := da.SetAspect("unknown", "Hp0")
+	// assert
+	require.Nil(err)
+	assert.Equal("Sh1", da.runableDevices["signal_1"].Runner.(Aspecter).Aspect())
+	assert.Contains(errUnknownAspect.Error(), "'Zs1' is unknown")
+	assert.Contains(errNoAspects.Error(), "provides no aspects")
+	assert.Contains(errUnknownDevice.Error(), "not found")
+}
+
+func Test_createBlinkingLamp(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{boardsIOAPI: &boardsIOAPIMock{}}
+	// act
+	rd, outputs, err := da.createBlinkingLamp(devicerecipe.Ingredients{StartingDelay: "1s"})
+	// assert
+	require.Nil(err)
+	assert.NotNil(rd)
+	assert.Equal(1, len(outputs))
+	assert.Implements((*Ticker)(nil), rd.Runner)
+}
+
+func Test_createCrossingFlasherGetOutPinSecErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{boardsIOAPI: &boardsIOAPIMock{}}
+	// act
+	rd, _, err := da.createCrossingFlasher(devicerecipe.Ingredients{BoardID: "error", BoardPinNrSec: 88})
+	// assert
+	require.NotNil(err)
+	assert.Nil(rd)
+	assert.Equal("test error", err.Error())
+}
+
+func Test_createDimmableLampGetAnalogOutPinErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{boardsIOAPI: &boardsIOAPIMock{}}
+	// act
+	rd, _, err := da.createDimmableLamp(devicerecipe.Ingredients{BoardID: "error", BoardPinNrPrim: 88})
+	// assert
+	require.NotNil(err)
+	assert.Nil(rd)
+	assert.Equal("test error", err.Error())
+}
+
+func Test_createServoTurnoutGetServoOutPinErrorGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := RailDeviceAPI{boardsIOAPI: &boardsIOAPIMock{}}
+	// act
+	rd, _, err := da.createServoTurnout(devicerecipe.Ingredients{BoardID: "error", BoardPinNrPrim: 88})
+	// assert
+	require.NotNil(err)
+	assert.Nil(rd)
+	assert.Equal("test error", err.Error())
+}
+
+func Test_getServoTravelTime(t *testing.T) {
+	// act
+	got := getServoTravelTime(devicerecipe.Ingredients{TravelSpeed: 51})
+	gotDefault := getServoTravelTime(devicerecipe.Ingredients{})
+	// assert
+	assert.Equal(t, 5*time.Second, got)
+	assert.Equal(t, 5100*time.Millisecond, gotDefault)
+}
+
+func Test_getTiming(t *testing.T) {
+	var getTimingTests = map[string]struct {
+		recipe devicerecipe.Ingredients
+		want   raildevices.Timing
+	}{
+		"Both":         {recipe: devicerecipe.Ingredients{StartingDelay: "1s", StoppingDelay: "250ms"}, want: raildevices.Timing{Starting: time.Second, Stopping: 250 * time.Millisecond}},
+		"WithoutStop":  {recipe: devicerecipe.Ingredients{StartingDelay: "1s"}, want: raildevices.Timing{Starting: time.Second, Stopping: time.Second}},
+		"ZeroStop":     {recipe: devicerecipe.Ingredients{StartingDelay: "1s", StoppingDelay: "0"}, want: raildevices.Timing{Starting: time.Second}},
+		"WithoutDelay": {recipe: devicerecipe.Ingredients{}, want: raildevices.Timing{}},
+	}
+	for name, gt := range getTimingTests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got := getTiming(gt.recipe)
+			// assert
+			assert.Equal(t, gt.want, got)
+		})
+	}
+}
+
+func Test_getBlinking(t *testing.T) {
+	var getBlinkingTests = map[string]struct {
+		recipe devicerecipe.Ingredients
+		want   raildevices.Blinking
+	}{
+		"Defaults": {recipe: devicerecipe.Ingredients{}, want: raildevices.Blinking{On: 500 * time.Millisecond, Off: 500 * time.Millisecond}},
+		"OnPeriod": {recipe: devicerecipe.Ingredients{OnPeriod: "1s"}, want: raildevices.Blinking{On: time.Second, Off: 500 * time.Millisecond}},
+		"Both":     {recipe: devicerecipe.Ingredients{OnPeriod: "200ms", OffPeriod: "0.8s"}, want: raildevices.Blinking{On: 200 * time.Millisecond, Off: 800 * time.Millisecond}},
+	}
+	for name, gt := range getBlinkingTests {
+		t.Run(name, func(t *testing.T) {
+			// act
+			got := getBlinking(gt.recipe)
+			// assert
+			assert.Equal(t, gt.want, got)
+		})
+	}
+}
+
 func Test_createTurnoutGetOutPinPrimErrorGetsError(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
@@ -301,7 +860,7 @@ func Test_createTurnoutGetOutPinPrimErrorGetsError(t *testing.T) {
 	da := RailDeviceAPI{boardsIOAPI: ba}
 	da.devices = make(map[string]struct{})
 	// act
-	_, err := da.createTurnout(devicerecipe.Ingredients{BoardID: "error", BoardPinNrPrim: 88})
+	_, _, err := da.createTurnout(devicerecipe.Ingredients{BoardID: "error", BoardPinNrPrim: 88})
 	// assert
 	require.NotNil(err)
 	assert.Equal("test error", err.Error())
@@ -315,7 +874,7 @@ func Test_createTurnoutGetOutPinSecErrorGetsError(t *testing.T) {
 	da := RailDeviceAPI{boardsIOAPI: ba}
 	da.devices = make(map[string]struct{})
 	// act
-	_, err := da.createTurnout(devicerecipe.Ingredients{BoardID: "error", BoardPinNrSec: 88})
+	_, _, err := da.createTurnout(devicerecipe.Ingredients{BoardID: "error", BoardPinNrSec: 88})
 	// assert
 	require.NotNil(err)
 	assert.Equal("test error", err.Error())
@@ -333,7 +892,36 @@ func (am boardsIOAPIMock) GetOutputPin(boardID string, boardPinNr uint8) (boardP
 	if boardID == "error" && boardPinNr == 88 {
 		err = fmt.Errorf("test error")
 	}
-	boardPin = &boardpin.Output{}
+	boardPin = &boardpin.Output{WriteValue: func(value uint8) (err error) {
+		if _, released := am.releasedPins[boardPinNr]; am.writtenValues != nil && !released {
+			am.writtenValues[boardPinNr] = value
+		}
+		return
+	}}
+	return
+}
+
+func (am boardsIOAPIMock) GetAnalogOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error) {
+	return am.GetOutputPin(boardID, boardPinNr)
+}
+
+func (am boardsIOAPIMock) GetServoOutputPin(boardID string, boardPinNr uint8) (boardPin *boardpin.Output, err error) {
+	return am.GetOutputPin(boardID, boardPinNr)
+}
+
+func (am boardsIOAPIMock) GetStoragePin(boardID string, boardPinNr uint8) (boardPin *boardpin.Storage, err error) {
+	if boardID == "error" && boardPinNr == 88 {
+		err = fmt.Errorf("test error")
+	}
+	boardPin = &boardpin.Storage{}
+	return
+}
+
+func (am boardsIOAPIMock) ReleasePin(boardID string, boardPinNr uint8) (err error) {
+	if boardID == "error" {
+		return fmt.Errorf("release error")
+	}
+	am.releasedPins[boardPinNr] = struct{}{}
 	return
 }
 
@@ -362,3 +950,8 @@ func (r runnerMock) SwitchOff() (err error) {
 }
 func (r runnerMock) StateChanged(visitor string) (hasChanged bool, err error) { return }
 func (r runnerMock) IsOn() bool                                               { return false }
+
+func (r tickerMock) Tick(now time.Time) (err error) {
+	*r.ticks = append(*r.ticks, now)
+	return
+}
@@ -2,13 +2,39 @@ package raildevicesapi
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
 )
 
+// stateRestorer is implemented by output devices, which keep their position without power, so a stored state can be
+// taken over without switching the outputs
+type stateRestorer interface {
+	RestoreState(state bool) (err error)
+}
+
+// busier is implemented by output devices, which complete the switching on later ticks
+type busier interface {
+	IsBusy() bool
+}
+
+// defectiver is implemented by output devices, which can be marked as defective, e.g. by a failed verification
+type defectiver interface {
+	IsDefective() (err error)
+}
+
 type runableDevice struct {
 	Runner
 	connectedInput Inputer
 	inputInversion bool
 	firstRun       bool
+	// a device detached by removing its input is skipped silently, a never connected device is reported once
+	wasConnected         bool
+	notConnectedReported bool
+	// optional, when given the state is stored after each switch and restored at first run
+	storage *boardpin.Storage
+	// the state is stored, when the switching is completed
+	storePending bool
 }
 
 func newRunableDevice(outDev Runner) *runableDevice {
@@ -28,12 +54,17 @@ func (o *runableDevice) Connect(inputDevice Inputer, inputInversion bool) (err e
 	}
 	o.connectedInput = inputDevice
 	o.inputInversion = inputInversion
+	o.wasConnected = true
 	return nil
 }
 
 // RunCommon is called in a loop and will make action, dependent on the input device
 func (o *runableDevice) Run() (err error) {
 	if o.connectedInput == nil {
+		if o.wasConnected || o.notConnectedReported {
+			return
+		}
+		o.notConnectedReported = true
 		return fmt.Errorf("The '%s' can't run, please map to an input first", o.RailDeviceName())
 	}
 	var changed bool
@@ -43,15 +74,87 @@ func (o *runableDevice) Run() (err error) {
 	if !(changed || o.firstRun) {
 		return
 	}
-	o.firstRun = false
-	if o.connectedInput.IsOn() != o.inputInversion {
+	switchOn := o.connectedInput.IsOn() != o.inputInversion
+	if o.firstRun {
+		o.firstRun = false
+		// the restored state is kept until the input changes, e.g. a button is always off at start
+		if o.restoreState() {
+			return
+		}
+	}
+	if switchOn {
 		err = o.SwitchOn()
 	} else {
 		err = o.SwitchOff()
 	}
+	if err == nil {
+		o.storePending = true
+		err = o.storeStateWhenCompleted()
+	}
 	return
 }
 
+// restoreState takes over the stored state, returns true when a valid state was restored
+// a not readable or not written memory (e.g. 0xFF) or a failed restore leads to switching by the input like without
+// storage
+func (o *runableDevice) restoreState() bool {
+	restorer, ok := o.Runner.(stateRestorer)
+	if o.storage == nil || !ok {
+		return false
+	}
+	value, err := o.storage.ReadValue()
+	if err != nil || value > 1 {
+		return false
+	}
+	return restorer.RestoreState(value == 1) == nil
+}
+
+// storeStateWhenCompleted stores the state after the switching is completed, a busy device is skipped until done
+// the state of a defective device is not stored, e.g. when the end position was not verified
+func (o *runableDevice) storeStateWhenCompleted() (err error) {
+	if !o.storePending {
+		return
+	}
+	if b, ok := o.Runner.(busier); ok && b.IsBusy() {
+		return
+	}
+	o.storePending = false
+	if d, ok := o.Runner.(defectiver); ok && d.IsDefective() != nil {
+		return
+	}
+	return o.storeState()
+}
+
+// storeState writes the current state to the storage, a memory with write deduplication is recommended
+func (o *runableDevice) storeState() (err error) {
+	if o.storage == nil {
+		return
+	}
+	var value uint8
+	if o.IsOn() {
+		value = 1
+	}
+	if err = o.storage.WriteValue(value); err != nil {
+		err = fmt.Errorf("Can't store the state of '%s', %w", o.RailDeviceName(), err)
+	}
+	return
+}
+
+// Tick forwards the time of the current cycle to devices with time dependent outputs
+func (o *runableDevice) Tick(now time.Time) (err error) {
+	if ticker, ok := o.Runner.(Ticker); ok {
+		if err = ticker.Tick(now); err != nil {
+			return
+		}
+	}
+	return o.storeStateWhenCompleted()
+}
+
+// IsConnected returns true, when an input is connected
+func (o *runableDevice) IsConnected() bool {
+	return o.connectedInput != nil
+}
+
 // ReleaseInput is used to unmap
 func (o *runableDevice) ReleaseInput() {
 	o.connectedInput = nil
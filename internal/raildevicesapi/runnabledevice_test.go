@@ -2,11 +2,23 @@ package raildevicesapi
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+	"github.com/gen2thomas/gobrail/internal/raildevices"
 )
 
+type storageTest struct {
+	stored     uint8
+	inputOn    bool
+	wantWrites int
+	wantStored uint8
+	wantOn     bool
+}
+
 func Test_newRunableDevice(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
@@ -76,6 +88,22 @@ func TestConnectWhenSelfConnectGetsError(t *testing.T) {
 	assert.Nil(rd.connectedInput)
 }
 
+func TestIsConnected(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	rd := runableDevice{Runner: runnerMock{name: "rdk"}}
+	// act
+	before := rd.IsConnected()
+	_ = rd.Connect(inputerMock{}, false)
+	connected := rd.IsConnected()
+	rd.ReleaseInput()
+	released := rd.IsConnected()
+	// assert
+	assert.False(before)
+	assert.True(connected)
+	assert.False(released)
+}
+
 func TestRun(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
@@ -96,9 +124,23 @@ func TestRunWithoutInputGetsError(t *testing.T) {
 	rd := runableDevice{Runner: runnerMock{name: "rdk"}}
 	// act
 	err := rd.Run()
+	errSecond := rd.Run()
 	// assert
 	require.NotNil(err)
 	assert.Contains(err.Error(), "map to an input first")
+	assert.Nil(errSecond)
+}
+
+func TestRunWithReleasedInputIsSkipped(t *testing.T) {
+	// arrange
+	require := require.New(t)
+	rd := runableDevice{Runner: runnerMock{name: "rdk"}}
+	require.Nil(rd.Connect(&inputerMock{}, false))
+	rd.ReleaseInput()
+	// act
+	err := rd.Run()
+	// assert
+	require.Nil(err)
 }
 
 func TestRunWhenStateChangedErrGetsError(t *testing.T) {
@@ -212,3 +254,155 @@ func TestReleaseInput(t *testing.T) {
 	// assert
 	assert.Nil(rd.connectedInput)
 }
+
+func TestRunWithStorage(t *testing.T) {
+	var storageTests = map[string]storageTest{
+		"RestoredFitsToInput":  {stored: 1, inputOn: true, wantStored: 1, wantOn: true},
+		"RestoredDiffers":      {stored: 0, inputOn: true, wantStored: 0, wantOn: false},
+		"RestoredOnInputOff":   {stored: 1, inputOn: false, wantStored: 1, wantOn: true},
+		"NotWrittenStorage":    {stored: 0xFF, inputOn: false, wantWrites: 2, wantStored: 0, wantOn: false},
+		"RestoredOffFitsToOff": {stored: 0, inputOn: false, wantStored: 0, wantOn: false},
+	}
+	for name, st := range storageTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			var writes int
+			output := &boardpin.Output{WriteValue: func(uint8) error { writes++; return nil }}
+			turnout := raildevices.NewTurnout(raildevices.NewCommonOutput("turnout", raildevices.Timing{}), output, output)
+			stored := st.stored
+			storage := &boardpin.Storage{
+				ReadValue:  func() (uint8, error) { return stored, nil },
+				WriteValue: func(value uint8) error { stored = value; return nil },
+			}
+			rd := newRunableDevice(turnout)
+			rd.storage = storage
+			require.Nil(rd.Connect(inputerMock{isOn: st.inputOn}, false))
+			// act
+			err := rd.Run()
+			// assert
+			require.Nil(err)
+			assert.Equal(st.wantWrites, writes)
+			assert.Equal(st.wantStored, stored)
+			assert.Equal(st.wantOn, turnout.IsOn())
+			assert.False(rd.firstRun)
+		})
+	}
+}
+
+func TestRunWithStorageSwitchesOnInputChangeAfterRestore(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	var writes int
+	output := &boardpin.Output{WriteValue: func(uint8) error { writes++; return nil }}
+	turnout := raildevices.NewTurnout(raildevices.NewCommonOutput("turnout", raildevices.Timing{}), output, output)
+	stored := uint8(1)
+	rd := newRunableDevice(turnout)
+	rd.storage = &boardpin.Storage{
+		ReadValue:  func() (uint8, error) { return stored, nil },
+		WriteValue: func(value uint8) error { stored = value; return nil },
+	}
+	require.Nil(rd.Connect(inputerMock{isOn: false}, false))
+	require.Nil(rd.Run())
+	writesAfterStart := writes
+	onAfterStart := turnout.IsOn()
+	rd.connectedInput = inputerMock{stateChanged: true, isOn: false}
+	// act
+	err := rd.Run()
+	// assert
+	require.Nil(err)
+	assert.Equal(0, writesAfterStart)
+	assert.True(onAfterStart)
+	assert.Equal(2, writes)
+	assert.False(turnout.IsOn())
+	assert.Equal(uint8(0), stored)
+}
+
+func TestRunWithStorageStoresWhenPositionVerified(t *testing.T) {
+	var verifyTests = map[string]struct {
+		feedback   uint8
+		wantStored uint8
+		wantErr    string
+	}{
+		"Reached":    {feedback: 1, wantStored: 1},
+		"NotReached": {feedback: 0, wantStored: 0xFF, wantErr: "has not reached the branch position"},
+	}
+	for name, vt := range verifyTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			output := &boardpin.Output{WriteValue: func(uint8) error { return nil }}
+			feedback := raildevices.TurnoutFeedback{
+				Branch:  &boardpin.Input{ReadValue: func() (uint8, error) { return vt.feedback, nil }},
+				Timeout: 100 * time.Millisecond,
+			}
+			co := raildevices.NewCommonOutput("turnout", raildevices.Timing{})
+			turnout := raildevices.NewTurnoutWithFeedback(co, output, output, feedback)
+			stored := uint8(0xFF)
+			rd := newRunableDevice(turnout)
+			rd.storage = &boardpin.Storage{
+				ReadValue:  func() (uint8, error) { return stored, nil },
+				WriteValue: func(value uint8) error { stored = value; return nil },
+			}
+			require.Nil(rd.Connect(inputerMock{isOn: true}, false))
+			start := time.Now()
+			// act
+			errRun := rd.Run()
+			storedAfterRun := stored
+			var errs []error
+			for _, tick := range []time.Duration{0, 100, 200} {
+				if err := rd.Tick(start.Add(tick * time.Millisecond)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			// assert
+			require.Nil(errRun)
+			assert.Equal(uint8(0xFF), storedAfterRun)
+			assert.Equal(vt.wantStored, stored)
+			assert.False(rd.storePending)
+			if vt.wantErr == "" {
+				assert.Empty(errs)
+			} else {
+				require.Equal(1, len(errs))
+				assert.Contains(errs[0].Error(), vt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunWithStorageStoresWhenSwitchingCompleted(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	var values []uint8
+	output := &boardpin.Output{WriteValue: func(value uint8) error { values = append(values, value); return nil }}
+	co := raildevices.NewCommonOutput("turnout", raildevices.Timing{Starting: 100 * time.Millisecond})
+	turnout := raildevices.NewTurnout(co, output, output)
+	stored := uint8(0xFF)
+	rd := newRunableDevice(turnout)
+	rd.storage = &boardpin.Storage{
+		ReadValue:  func() (uint8, error) { return stored, nil },
+		WriteValue: func(value uint8) error { stored = value; return nil },
+	}
+	require.Nil(rd.Connect(inputerMock{isOn: true}, false))
+	start := time.Now()
+	// act
+	errRun := rd.Run()
+	storedAfterRun := stored
+	errTick := rd.Tick(start)
+	storedAfterTick := stored
+	errDone := rd.Tick(start.Add(100 * time.Millisecond))
+	// assert
+	require.Nil(errRun)
+	require.Nil(errTick)
+	require.Nil(errDone)
+	assert.Equal(uint8(0xFF), storedAfterRun)
+	assert.Equal(uint8(0xFF), storedAfterTick)
+	assert.Equal(uint8(1), stored)
+	assert.Equal([]uint8{1, 0}, values)
+	assert.True(turnout.IsOn())
+	assert.False(rd.storePending)
+}
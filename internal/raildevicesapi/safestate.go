@@ -0,0 +1,118 @@
+package raildevicesapi
+
+import (
+	"fmt"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+	"github.com/gen2thomas/gobrail/internal/devicerecipe"
+	"github.com/gen2thomas/gobrail/internal/errwrap"
+)
+
+// safeOutputs are the outputs of a device with the values to write for the safe state
+type safeOutputs struct {
+	outputs  []*boardpin.Output
+	values   []uint8
+	keepLast bool
+}
+
+// verifySafeState checks the count of values fits to the count of used board pins before creating the device
+// a value other than 0 is rejected for coils, no value is accepted for servos
+func verifySafeState(deviceRecipe devicerecipe.Ingredients) (err error) {
+	var values []uint8
+	if values, _, err = devicerecipe.ParseSafeState(deviceRecipe.SafeState); err != nil {
+		return
+	}
+	if pinsCount := len(usedBoardPins(deviceRecipe)); len(values) > 1 && len(values) != pinsCount {
+		return fmt.Errorf("The safe state '%s' of rail device '%s' needs 1 or %d values", deviceRecipe.SafeState,
+			deviceRecipe.Name, pinsCount)
+	}
+	if len(values) > 0 && devicerecipe.TypeMap[deviceRecipe.Type] == devicerecipe.ServoTurnout {
+		return fmt.Errorf("The safe state '%s' of rail device '%s' would move the servo", deviceRecipe.SafeState,
+			deviceRecipe.Name)
+	}
+	coilsCount := pulsedCoilsCount(deviceRecipe)
+	if coilsCount == 0 {
+		return
+	}
+	for i, value := range values {
+		if value != 0 && (len(values) == 1 || i < coilsCount) {
+			return fmt.Errorf("The safe state '%s' of rail device '%s' would energize a coil permanently",
+				deviceRecipe.SafeState, deviceRecipe.Name)
+		}
+	}
+	return
+}
+
+// pulsedCoilsCount gets the count of outputs with coils at start of the used board pins, a coil must not be energized
+// permanently by the safe state
+func pulsedCoilsCount(deviceRecipe devicerecipe.Ingredients) int {
+	switch devicerecipe.TypeMap[deviceRecipe.Type] {
+	case devicerecipe.Turnout, devicerecipe.SemaphoreSignal:
+		return 2
+	}
+	return 0
+}
+
+// newSafeOutputs creates the safe state for the given outputs, the recipe needs to be verified before
+func newSafeOutputs(deviceRecipe devicerecipe.Ingredients, outputs []*boardpin.Output) *safeOutputs {
+	values, keepLast, _ := devicerecipe.ParseSafeState(deviceRecipe.SafeState)
+	s := &safeOutputs{outputs: outputs, values: make([]uint8, len(outputs)), keepLast: keepLast}
+	for i := range s.values {
+		switch len(values) {
+		case 0:
+			// off
+		case 1:
+			s.values[i] = values[0]
+		default:
+			s.values[i] = values[i]
+		}
+	}
+	return s
+}
+
+// completer is implemented by output devices with pending steps of switching, e.g. the end of a coil pulse
+type completer interface {
+	CompletePending() (err error)
+}
+
+// ApplySafeStates writes the safe state to the outputs of all devices in order of adding the devices
+// pending steps of switching are completed before without delay, so no coil is left energized also for "Last"
+// the devices are not involved in writing the safe state, it should be followed by writing pending outputs
+func (di *RailDeviceAPI) ApplySafeStates() (err error) {
+	di.mutex.Lock()
+	defer di.mutex.Unlock()
+
+	for _, railDeviceKey := range di.order {
+		if runableDevice, ok := di.runableDevices[railDeviceKey]; ok {
+			if c, ok := runableDevice.Runner.(completer); ok {
+				err = errwrap.Wrap(err, c.CompletePending())
+			}
+		}
+		if safe, ok := di.safeStates[railDeviceKey]; ok {
+			err = errwrap.Wrap(err, safe.apply())
+		}
+	}
+	return
+}
+
+func (s *safeOutputs) apply() (err error) {
+	if s.keepLast {
+		return
+	}
+	for i, output := range s.outputs {
+		err = errwrap.Wrap(err, output.WriteValue(s.values[i]))
+	}
+	return
+}
+
+// applyForRemove writes the safe state before the outputs are released, "Last" is not kept for a removed device,
+// so all outputs are switched off
+func (s *safeOutputs) applyForRemove() (err error) {
+	if !s.keepLast {
+		return s.apply()
+	}
+	for _, output := range s.outputs {
+		err = errwrap.Wrap(err, output.WriteValue(0))
+	}
+	return
+}
@@ -0,0 +1,171 @@
+package raildevicesapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gen2thomas/gobrail/internal/boardpin"
+	"github.com/gen2thomas/gobrail/internal/boardrecipe"
+	"github.com/gen2thomas/gobrail/internal/boardsapi"
+	"github.com/gen2thomas/gobrail/internal/devicerecipe"
+)
+
+func TestVerifySafeState(t *testing.T) {
+	var verifyTests = map[string]struct {
+		recipe  devicerecipe.Ingredients
+		wantErr string
+	}{
+		"Off":         {recipe: devicerecipe.Ingredients{Type: "Turnout", SafeState: "Off"}},
+		"SingleValue": {recipe: devicerecipe.Ingredients{Type: "Lamp", SafeState: "1"}},
+		"ValuePerPin": {recipe: devicerecipe.Ingredients{Type: "TwoLightsSignal", SafeState: "0,1"}},
+		"TurnoutCoil": {
+			recipe:  devicerecipe.Ingredients{Name: "T1", Type: "Turnout", SafeState: "1"},
+			wantErr: "safe state '1' of rail device 'T1' would energize a coil",
+		},
+		"SemaphoreCoil": {
+			recipe:  devicerecipe.Ingredients{Type: "SemaphoreSignal", AuxOutput: true, SafeState: "0,1,0"},
+			wantErr: "would energize a coil",
+		},
+		"SemaphoreAllWithAux": {
+			recipe:  devicerecipe.Ingredients{Type: "SemaphoreSignal", AuxOutput: true, SafeState: "1"},
+			wantErr: "would energize a coil",
+		},
+		"ServoValue": {
+			recipe:  devicerecipe.Ingredients{Type: "ServoTurnout", SafeState: "0"},
+			wantErr: "would move the servo",
+		},
+		"ServoLast":    {recipe: devicerecipe.Ingredients{Type: "ServoTurnout", SafeState: "Last"}},
+		"SemaphoreAux": {recipe: devicerecipe.Ingredients{Type: "SemaphoreSignal", AuxOutput: true, SafeState: "0,0,1"}},
+		"NotParsable":  {recipe: devicerecipe.Ingredients{Type: "Lamp", SafeState: "On"}, wantErr: "invalid syntax"},
+		"ValuesCount": {
+			recipe:  devicerecipe.Ingredients{Name: "Lamp1", Type: "Lamp", SafeState: "0,1"},
+			wantErr: "safe state '0,1' of rail device 'Lamp1' needs 1 or 1 values",
+		},
+	}
+	for name, vt := range verifyTests {
+		t.Run(name, func(t *testing.T) {
+			// arrange
+			assert := assert.New(t)
+			require := require.New(t)
+			// act
+			err := verifySafeState(vt.recipe)
+			// assert
+			if vt.wantErr == "" {
+				assert.Nil(err)
+			} else {
+				require.NotNil(err)
+				assert.Contains(err.Error(), vt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewSafeOutputs(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	outputs := []*boardpin.Output{{}, {}}
+	// act
+	off := newSafeOutputs(devicerecipe.Ingredients{}, outputs)
+	single := newSafeOutputs(devicerecipe.Ingredients{SafeState: "1"}, outputs)
+	perPin := newSafeOutputs(devicerecipe.Ingredients{SafeState: "1,0"}, outputs)
+	last := newSafeOutputs(devicerecipe.Ingredients{SafeState: "Last"}, outputs)
+	// assert
+	assert.Equal([]uint8{0, 0}, off.values)
+	assert.Equal([]uint8{1, 1}, single.values)
+	assert.Equal([]uint8{1, 0}, perPin.values)
+	assert.True(last.keepLast)
+}
+
+func TestApplySafeStates(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := NewRailDevicesAPI(&boardsIOAPIMock{})
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "button", Type: "Button", BoardPinNrPrim: 0}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp", Type: "Lamp", BoardPinNrPrim: 1}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "signal", Type: "TwoLightsSignal", BoardPinNrPrim: 2,
+		BoardPinNrSec: 3, SafeState: "0,1"}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp_last", Type: "Lamp", BoardPinNrPrim: 4,
+		SafeState: "Last"}))
+	var written []string
+	for _, name := range []string{"lamp", "signal", "lamp_last"} {
+		for i, output := range da.safeStates[name].outputs {
+			pinName := fmt.Sprintf("%s_%d", name, i)
+			output.WriteValue = func(value uint8) (err error) {
+				written = append(written, fmt.Sprintf("%s=%d", pinName, value))
+				return
+			}
+		}
+	}
+	// act
+	err := da.ApplySafeStates()
+	// assert
+	require.Nil(err)
+	assert.Equal([]string{"lamp_0=0", "signal_0=0", "signal_1=1"}, written)
+}
+
+func TestApplySafeStatesCompletesPendingSwitching(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := NewRailDevicesAPI(&boardsIOAPIMock{})
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "turnout", Type: "Turnout", BoardPinNrPrim: 1,
+		BoardPinNrSec: 2, StartingDelay: "1s", SafeState: "Last"}))
+	var written []string
+	for i, output := range da.safeStates["turnout"].outputs {
+		pinName := fmt.Sprintf("turnout_%d", i)
+		output.WriteValue = func(value uint8) (err error) {
+			written = append(written, fmt.Sprintf("%s=%d", pinName, value))
+			return
+		}
+	}
+	turnout := da.runableDevices["turnout"].Runner
+	require.Nil(turnout.SwitchOn())
+	// act
+	err := da.ApplySafeStates()
+	// assert
+	require.Nil(err)
+	assert.Equal([]string{"turnout_0=1", "turnout_0=0"}, written)
+	assert.True(turnout.IsOn())
+}
+
+func TestApplySafeStatesContinuesAfterError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	da := NewRailDevicesAPI(&boardsIOAPIMock{})
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp1", Type: "Lamp", BoardPinNrPrim: 1}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp2", Type: "Lamp", BoardPinNrPrim: 2}))
+	da.safeStates["lamp1"].outputs[0].WriteValue = func(value uint8) error { return fmt.Errorf("write error") }
+	var lamp2Written bool
+	da.safeStates["lamp2"].outputs[0].WriteValue = func(value uint8) (err error) {
+		lamp2Written = true
+		return
+	}
+	// act
+	err := da.ApplySafeStates()
+	// assert
+	require.NotNil(err)
+	assert.Contains(err.Error(), "write error")
+	assert.True(lamp2Written)
+}
+
+func TestRemoveDeviceRemovesSafeState(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	ba := boardsapi.NewBoardsAPI(nil)
+	require.Nil(ba.AddBoard(boardrecipe.Ingredients{Name: "test_board", Type: "Virtual8io"}))
+	da := NewRailDevicesAPI(ba)
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp1", Type: "Lamp", BoardID: "test_board", BoardPinNrPrim: 1}))
+	require.Nil(da.AddDevice(devicerecipe.Ingredients{Name: "lamp2", Type: "Lamp", BoardID: "test_board", BoardPinNrPrim: 2}))
+	// act
+	err := da.RemoveDevice("lamp1")
+	// assert
+	require.Nil(err)
+	assert.NotContains(da.safeStates, "lamp1")
+	assert.Equal([]string{"lamp2"}, da.order)
+}
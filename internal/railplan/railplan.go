@@ -66,6 +66,16 @@ func (p CookBook) enhanceAndVerify() (err error) {
 	return
 }
 
+// NeedsAdaptor states true when at least one board recipe needs a real adaptor
+func (p CookBook) NeedsAdaptor() bool {
+	for _, boardRecipe := range p.BoardRecipes {
+		if boardRecipe.NeedsAdaptor() {
+			return true
+		}
+	}
+	return false
+}
+
 // AddBoardRecipe read and add a board to menu card
 func (p *CookBook) AddBoardRecipe(boardFile string) (err error) {
 	var recipe boardrecipe.Ingredients
@@ -55,6 +55,16 @@ func Test_enhanceAndVerifyDeviceErrorGetsError(t *testing.T) {
 	// other stuff is tested by "devicerecipe_test.go"
 }
 
+func TestNeedsAdaptor(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	virtualBook := &CookBook{BoardRecipes: []boardrecipe.Ingredients{{Type: "Virtual8io"}, {Type: "Virtual8io"}}}
+	mixedBook := &CookBook{BoardRecipes: []boardrecipe.Ingredients{{Type: "Virtual8io"}, {Type: "Type2o"}}}
+	// act & assert
+	assert.Equal(false, virtualBook.NeedsAdaptor())
+	assert.Equal(true, mixedBook.NeedsAdaptor())
+}
+
 func TestAddBoardRecipe(t *testing.T) {
 	// arrange
 	assert := assert.New(t)
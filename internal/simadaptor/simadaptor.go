@@ -0,0 +1,292 @@
+package simadaptor
+
+// The simulation adaptor is a software i2c bus, which can be used instead of a real gobot adaptor
+//
+//      Author: g2t
+//  Created on: 17.10.2026
+// Called from: gobrailcreator
+// Call       : nothing, all chips are simulated in memory
+//
+// At each possible address a PCA9501 is emulated:
+// - 0x00..0x3F GPIO part, the port is quasi bidirectional (read value = output latch AND input level)
+// - 0x40..0x7F EEPROM part of the GPIO address with bit 6 cleared, 256 byte
+//
+// Functions:
+// + implements i2c.Connector and gobot.Connection
+// + simulate input levels from outside, e.g. from a test
+// + get output latch of a simulated chip
+// + simulate a power on reset of a chip
+//
+
+import (
+	"fmt"
+	"sync"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+const eepromFlag = 0x40
+const maxAddress = 0x7F
+
+type pca9501 struct {
+	latch     uint8
+	levels    uint8
+	eeprom    [256]uint8
+	eepromPtr uint8
+}
+
+// Adaptor is the simulated i2c bus
+type Adaptor struct {
+	name    string
+	mutex   *sync.Mutex
+	devices map[int]*pca9501
+}
+
+type gpioConnection struct {
+	adaptor *Adaptor
+	device  *pca9501
+}
+
+type eepromConnection struct {
+	adaptor *Adaptor
+	device  *pca9501
+}
+
+// NewAdaptor creates a new simulation adaptor
+func NewAdaptor() *Adaptor {
+	return &Adaptor{
+		name:    gobot.DefaultName("Simulation"),
+		mutex:   &sync.Mutex{},
+		devices: make(map[int]*pca9501),
+	}
+}
+
+// Name returns the name of the adaptor
+func (a *Adaptor) Name() string { return a.name }
+
+// SetName sets the name of the adaptor
+func (a *Adaptor) SetName(name string) { a.name = name }
+
+// Connect does nothing, the bus is always there
+func (a *Adaptor) Connect() (err error) { return }
+
+// Finalize does nothing, the simulated chips keep there values like a real hardware
+func (a *Adaptor) Finalize() (err error) { return }
+
+// GetDefaultBus returns the default i2c bus
+func (a *Adaptor) GetDefaultBus() int { return 0 }
+
+// GetConnection returns a connection to the simulated chip at the given address
+func (a *Adaptor) GetConnection(address int, bus int) (connection i2c.Connection, err error) {
+	if address < 0 || address > maxAddress {
+		return nil, fmt.Errorf("Address 0x%02X not supported by simulation", address)
+	}
+	device := a.getDevice(address &^ eepromFlag)
+	if address&eepromFlag > 0 {
+		return &eepromConnection{adaptor: a, device: device}, nil
+	}
+	return &gpioConnection{adaptor: a, device: device}, nil
+}
+
+// SetInputLevel simulates the external level (0, 1) of a GPIO pin at the chip with the given GPIO address
+func (a *Adaptor) SetInputLevel(address int, pin uint8, level uint8) {
+	device := a.getDevice(address &^ eepromFlag)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if level == 0 {
+		device.levels &^= 1 << pin
+	} else {
+		device.levels |= 1 << pin
+	}
+}
+
+// PowerOnReset simulates a power cycle of the chip with the given GPIO address, all pins are high afterwards and
+// the EEPROM keeps its content
+func (a *Adaptor) PowerOnReset(address int) {
+	device := a.getDevice(address &^ eepromFlag)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	device.latch = 0xFF
+}
+
+// Port gets the output latch of the chip with the given GPIO address
+func (a *Adaptor) Port(address int) uint8 {
+	device := a.getDevice(address &^ eepromFlag)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return device.latch
+}
+
+func (a *Adaptor) getDevice(address int) *pca9501 {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	device, ok := a.devices[address]
+	if !ok {
+		// after power on all pins are high
+		device = &pca9501{latch: 0xFF, levels: 0xFF}
+		for i := range device.eeprom {
+			device.eeprom[i] = 0xFF
+		}
+		a.devices[address] = device
+	}
+	return device
+}
+
+// Read reads the port value into all given bytes
+func (c *gpioConnection) Read(data []byte) (read int, err error) {
+	for i := range data {
+		if data[i], err = c.ReadByte(); err != nil {
+			return
+		}
+		read++
+	}
+	return
+}
+
+// Write writes all given bytes to the port, the last one remains
+func (c *gpioConnection) Write(data []byte) (written int, err error) {
+	for _, val := range data {
+		if err = c.WriteByte(val); err != nil {
+			return
+		}
+		written++
+	}
+	return
+}
+
+// Close does nothing
+func (c *gpioConnection) Close() (err error) { return }
+
+// ReadByte reads the port
+func (c *gpioConnection) ReadByte() (val byte, err error) {
+	c.adaptor.mutex.Lock()
+	defer c.adaptor.mutex.Unlock()
+
+	return c.device.latch & c.device.levels, nil
+}
+
+// WriteByte writes the output latch
+func (c *gpioConnection) WriteByte(val byte) (err error) {
+	c.adaptor.mutex.Lock()
+	defer c.adaptor.mutex.Unlock()
+
+	c.device.latch = val
+	return
+}
+
+// ReadByteData is not supported by the GPIO part
+func (c *gpioConnection) ReadByteData(reg uint8) (val uint8, err error) {
+	return 0, fmt.Errorf("ReadByteData not supported by GPIO simulation")
+}
+
+// ReadWordData is not supported by the GPIO part
+func (c *gpioConnection) ReadWordData(reg uint8) (val uint16, err error) {
+	return 0, fmt.Errorf("ReadWordData not supported by GPIO simulation")
+}
+
+// WriteByteData is not supported by the GPIO part
+func (c *gpioConnection) WriteByteData(reg uint8, val uint8) (err error) {
+	return fmt.Errorf("WriteByteData not supported by GPIO simulation")
+}
+
+// WriteWordData is not supported by the GPIO part
+func (c *gpioConnection) WriteWordData(reg uint8, val uint16) (err error) {
+	return fmt.Errorf("WriteWordData not supported by GPIO simulation")
+}
+
+// WriteBlockData is not supported by the GPIO part
+func (c *gpioConnection) WriteBlockData(reg uint8, b []byte) (err error) {
+	return fmt.Errorf("WriteBlockData not supported by GPIO simulation")
+}
+
+// Read reads sequential from the current EEPROM address
+func (c *eepromConnection) Read(data []byte) (read int, err error) {
+	for i := range data {
+		if data[i], err = c.ReadByte(); err != nil {
+			return
+		}
+		read++
+	}
+	return
+}
+
+// Write sets the EEPROM address with the first byte and writes the remaining bytes sequential
+func (c *eepromConnection) Write(data []byte) (written int, err error) {
+	if len(data) == 0 {
+		return
+	}
+	if err = c.WriteBlockData(data[0], data[1:]); err != nil {
+		return
+	}
+	return len(data), nil
+}
+
+// Close does nothing
+func (c *eepromConnection) Close() (err error) { return }
+
+// ReadByte reads the EEPROM at the current address and increments the address
+func (c *eepromConnection) ReadByte() (val byte, err error) {
+	c.adaptor.mutex.Lock()
+	defer c.adaptor.mutex.Unlock()
+
+	val = c.device.eeprom[c.device.eepromPtr]
+	c.device.eepromPtr++
+	return
+}
+
+// WriteByte sets the current EEPROM address
+func (c *eepromConnection) WriteByte(val byte) (err error) {
+	c.adaptor.mutex.Lock()
+	defer c.adaptor.mutex.Unlock()
+
+	c.device.eepromPtr = val
+	return
+}
+
+// ReadByteData reads the EEPROM at the given address
+func (c *eepromConnection) ReadByteData(reg uint8) (val uint8, err error) {
+	if err = c.WriteByte(reg); err != nil {
+		return
+	}
+	return c.ReadByte()
+}
+
+// ReadWordData reads two bytes of EEPROM, beginning at the given address
+func (c *eepromConnection) ReadWordData(reg uint8) (val uint16, err error) {
+	var low, high uint8
+	if low, err = c.ReadByteData(reg); err != nil {
+		return
+	}
+	if high, err = c.ReadByte(); err != nil {
+		return
+	}
+	return uint16(high)<<8 | uint16(low), nil
+}
+
+// WriteByteData writes the value to the given EEPROM address
+func (c *eepromConnection) WriteByteData(reg uint8, val uint8) (err error) {
+	return c.WriteBlockData(reg, []byte{val})
+}
+
+// WriteWordData writes two bytes to the EEPROM, beginning at the given address
+func (c *eepromConnection) WriteWordData(reg uint8, val uint16) (err error) {
+	return c.WriteBlockData(reg, []byte{uint8(val), uint8(val >> 8)})
+}
+
+// WriteBlockData writes all bytes to the EEPROM, beginning at the given address
+func (c *eepromConnection) WriteBlockData(reg uint8, b []byte) (err error) {
+	c.adaptor.mutex.Lock()
+	defer c.adaptor.mutex.Unlock()
+
+	c.device.eepromPtr = reg
+	for _, val := range b {
+		c.device.eeprom[c.device.eepromPtr] = val
+		c.device.eepromPtr++
+	}
+	return
+}
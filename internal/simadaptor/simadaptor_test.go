@@ -0,0 +1,149 @@
+package simadaptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/drivers/i2c"
+)
+
+var _ i2c.Connector = (*Adaptor)(nil)
+var _ gobot.Connection = (*Adaptor)(nil)
+
+func TestNewAdaptor(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	// act
+	a := NewAdaptor()
+	// assert
+	require.NotNil(a)
+	assert.Contains(a.Name(), "Simulation")
+	assert.Nil(a.Connect())
+	assert.Nil(a.Finalize())
+	assert.Equal(0, a.GetDefaultBus())
+}
+
+func TestGetConnectionWrongAddressGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	a := NewAdaptor()
+	// act
+	con, err := a.GetConnection(0x80, 0)
+	// assert
+	require.NotNil(err)
+	assert.Nil(con)
+	assert.Contains(err.Error(), "not supported")
+}
+
+func TestGPIOReadWrite(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	a := NewAdaptor()
+	con, _ := a.GetConnection(0x04, 0)
+	// act
+	val0, err0 := con.ReadByte()
+	err1 := con.WriteByte(0x0F)
+	val1, err2 := con.ReadByte()
+	a.SetInputLevel(0x04, 1, 0)
+	val2, err3 := con.ReadByte()
+	// assert
+	require.Nil(err0)
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	assert.Equal(uint8(0xFF), val0)
+	assert.Equal(uint8(0x0F), val1)
+	assert.Equal(uint8(0x0D), val2)
+	assert.Equal(uint8(0x0F), a.Port(0x04))
+}
+
+func TestGPIOOtherAddressIsIndependent(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	a := NewAdaptor()
+	con4, _ := a.GetConnection(0x04, 0)
+	con5, _ := a.GetConnection(0x05, 0)
+	// act
+	con4.WriteByte(0x00)
+	val, _ := con5.ReadByte()
+	// assert
+	assert.Equal(uint8(0xFF), val)
+}
+
+func TestGPIODataFunctionsGetsError(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	a := NewAdaptor()
+	con, _ := a.GetConnection(0x04, 0)
+	// act
+	_, err1 := con.ReadByteData(1)
+	_, err2 := con.ReadWordData(1)
+	err3 := con.WriteByteData(1, 2)
+	err4 := con.WriteWordData(1, 2)
+	err5 := con.WriteBlockData(1, []byte{2})
+	// assert
+	assert.NotNil(err1)
+	assert.NotNil(err2)
+	assert.NotNil(err3)
+	assert.NotNil(err4)
+	assert.NotNil(err5)
+}
+
+func TestEEPROMReadWrite(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	a := NewAdaptor()
+	con, _ := a.GetConnection(0x04|0x40, 0)
+	// act
+	val0, err0 := con.ReadByteData(0x10)
+	err1 := con.WriteByteData(0x10, 0x5A)
+	val1, err2 := con.ReadByteData(0x10)
+	err3 := con.WriteWordData(0x20, 0x1234)
+	val2, err4 := con.ReadWordData(0x20)
+	// assert
+	require.Nil(err0)
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	require.Nil(err4)
+	assert.Equal(uint8(0xFF), val0)
+	assert.Equal(uint8(0x5A), val1)
+	assert.Equal(uint16(0x1234), val2)
+}
+
+func TestEEPROMWriteReadSequential(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	require := require.New(t)
+	a := NewAdaptor()
+	con, _ := a.GetConnection(0x44, 0)
+	data := make([]byte, 3)
+	// act
+	written, err1 := con.Write([]byte{0x30, 1, 2, 3})
+	err2 := con.WriteByte(0x30)
+	read, err3 := con.Read(data)
+	// assert
+	require.Nil(err1)
+	require.Nil(err2)
+	require.Nil(err3)
+	assert.Equal(4, written)
+	assert.Equal(3, read)
+	assert.Equal([]byte{1, 2, 3}, data)
+}
+
+func TestEEPROMDoesNotChangeGPIO(t *testing.T) {
+	// arrange
+	assert := assert.New(t)
+	a := NewAdaptor()
+	conMem, _ := a.GetConnection(0x44, 0)
+	// act
+	conMem.WriteByteData(0x00, 0x00)
+	// assert
+	assert.Equal(uint8(0xFF), a.Port(0x04))
+}